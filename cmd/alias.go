@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+// Alias is a named description, client and tags for new entries, e.g.
+//
+//	aliases:
+//	  review:
+//	    description: Code review
+//	    client: acme
+//	    tags: [review]
+//
+// so that "ttrack start @review" starts a code review entry for acme and
+// "ttrack start '@review PR 42'" starts "Code review PR 42".
+type Alias struct {
+	Description string
+	// Client is the nickname of a client.
+	Client string
+	Tags   []string
+	NoBill bool
+}
+
+// expandAlias expands a description that starts with "@" to the
+// description of the alias with that name, followed by any text after the
+// name. Other descriptions are returned as is with an empty alias.
+func expandAlias(description string) (string, Alias, error) {
+	if !strings.HasPrefix(description, "@") {
+		return description, Alias{}, nil
+	}
+	parts := strings.SplitN(description[1:], " ", 2)
+	// Config keys are lower case.
+	alias, ok := cfg.Aliases[strings.ToLower(parts[0])]
+	if !ok {
+		return "", Alias{}, fmt.Errorf("no alias found with name: %s", parts[0])
+	}
+	if len(parts) == 2 {
+		description = strings.TrimSpace(alias.Description + " " + strings.TrimSpace(parts[1]))
+	} else {
+		description = alias.Description
+	}
+	return description, alias, nil
+}
+
+// entryClient returns the client from --client, the client of the alias or
+// the current client.
+func entryClient(alias Alias) track.Client {
+	switch {
+	case clientFilterArg != "":
+		return lookupClient(clientFilterArg)
+	case alias.Client != "":
+		return lookupClient(alias.Client)
+	default:
+		return cfg.CurrentClient
+	}
+}
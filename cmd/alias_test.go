@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+func TestExpandAlias(t *testing.T) {
+	defer func() { cfg = Config{} }()
+	cfg.Aliases = map[string]Alias{
+		"review": {Description: "Code review", Client: "acme", Tags: []string{"review"}},
+	}
+
+	tests := []struct {
+		description string
+		expanded    string
+		client      string
+		err         bool
+	}{
+		{"@review", "Code review", "acme", false},
+		{"@review PR 42", "Code review PR 42", "acme", false},
+		{"@Review  PR 42 ", "Code review PR 42", "acme", false},
+		{"@standup", "", "", true},
+		{"Write docs @review", "Write docs @review", "", false},
+	}
+	for _, test := range tests {
+		expanded, alias, err := expandAlias(test.description)
+		if (err != nil) != test.err {
+			t.Errorf("(%s) unexpected error: %v", test.description, err)
+			continue
+		}
+		if expanded != test.expanded || alias.Client != test.client {
+			t.Errorf("(%s) expected %q for %q, got %q for %q", test.description, test.expanded, test.client, expanded, alias.Client)
+		}
+	}
+}
+
+func TestEntryClient(t *testing.T) {
+	defer func() { cfg, clientFilterArg = Config{}, "" }()
+	cfg.Clients = []track.Client{{Nickname: "acme", ClientID: 1}, {Nickname: "globex", ClientID: 2}}
+	cfg.CurrentClient = cfg.Clients[0]
+
+	if client := entryClient(Alias{}); client.Nickname != "acme" {
+		t.Errorf("Expected the current client, got %v", client)
+	}
+	if client := entryClient(Alias{Client: "globex"}); client.Nickname != "globex" {
+		t.Errorf("Expected the client of the alias, got %v", client)
+	}
+	clientFilterArg = "acme"
+	if client := entryClient(Alias{Client: "globex"}); client.Nickname != "acme" {
+		t.Errorf("Expected --client to override the alias, got %v", client)
+	}
+}
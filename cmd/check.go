@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+var maxDurationArg string
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the log for problems.",
+	Long: `Check the log for overlapping entries, negative or zero durations,
+duplicate external IDs, and suspiciously long entries.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		maxDuration, err := timeexpr.ParseDuration(maxDurationArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		client := oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		}
+		tracker := GetTracker(client)
+
+		problems := track.Check(tracker.LoadEntries(), maxDuration)
+		if len(problems) == 0 {
+			fmt.Println("No problems found.")
+			return
+		}
+		for _, problem := range problems {
+			fmt.Println(problem.String())
+		}
+		fmt.Println()
+		fmt.Println("Found", len(problems), "problems.")
+		os.Exit(1)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	checkCmd.Flags().StringVar(&maxDurationArg, "max", "12h", "Report entries that are longer than this duration.")
+}
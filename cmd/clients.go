@@ -141,6 +141,7 @@ func init() {
 	rootCmd.AddCommand(clientCmd)
 	clientCmd.AddCommand(addClientCmd)
 	clientCmd.AddCommand(setCurrentClientCmd)
+	setCurrentClientCmd.ValidArgsFunction = completeClients
 	clientCmd.AddCommand(listClientsCommand)
 	clientCmd.AddCommand(getCurrentClientCmd)
 
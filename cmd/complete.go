@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+// maxCompletions limits the number of descriptions and IDs that are
+// suggested.
+const maxCompletions = 50
+
+// recentEntries loads the entries from the local log, most recent first.
+// Completions only read the local log so that they are fast.
+func recentEntries() []track.Entry {
+	local := track.Local{LogLocation: logLocation}
+	entries := local.LoadEntries()
+	sort.SliceStable(entries, func(i int, j int) bool { return entries[i].StartedAt.After(entries[j].StartedAt) })
+	return entries
+}
+
+// completeDescriptions suggests aliases and the descriptions of recent
+// entries for the description argument.
+func completeDescriptions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	prefix := strings.ToLower(toComplete)
+	res := []string{}
+	for name, alias := range cfg.Aliases {
+		if strings.HasPrefix("@"+name, prefix) {
+			res = append(res, "@"+name+"\t"+alias.Description)
+		}
+	}
+	sort.Strings(res)
+	if strings.HasPrefix(toComplete, "@") {
+		return res, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range recentEntries() {
+		description := entry.Description
+		key := strings.ToLower(description)
+		if description == "" || seen[key] || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		seen[key] = true
+		res = append(res, description)
+		if len(seen) == maxCompletions {
+			break
+		}
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeClients suggests client nicknames.
+func completeClients(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	res := []string{}
+	for _, client := range cfg.Clients {
+		if strings.HasPrefix(client.Nickname, toComplete) {
+			completion := client.Nickname
+			if client.Name != "" {
+				completion += "\t" + client.Name
+			}
+			res = append(res, completion)
+		}
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEntryIDs suggests the IDs of recent entries with their
+// descriptions.
+func completeEntryIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	res := []string{}
+	for _, entry := range recentEntries() {
+		id := strconv.Itoa(entry.ID)
+		if !strings.HasPrefix(id, toComplete) {
+			continue
+		}
+		res = append(res, id+"\t"+entry.Description)
+		if len(res) == maxCompletions {
+			break
+		}
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeOneEntryID suggests entry IDs for the first argument.
+func completeOneEntryID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeEntryIDs(cmd, args, toComplete)
+}
+
+// registerClientCompletion completes client nicknames for the flag.
+func registerClientCompletion(cmd *cobra.Command, flag string) {
+	if err := cmd.RegisterFlagCompletionFunc(flag, completeClients); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+// completionLog saves entries with the descriptions to a temporary log,
+// one hour apart, and uses it as the log location.
+func completionLog(t *testing.T, descriptions ...string) func() {
+	dir, err := ioutil.TempDir("", "ttrack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logLocation = filepath.Join(dir, "log.json")
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	entries := []track.Entry{}
+	for ix, description := range descriptions {
+		at := started.Add(time.Duration(ix) * time.Hour)
+		entries = append(entries, track.Entry{Description: description, StartedAt: at, FinishedAt: at.Add(time.Hour), Duration: 3600})
+	}
+	local := track.Local{LogLocation: logLocation}
+	local.SaveEntries(entries)
+	return func() {
+		os.RemoveAll(dir)
+		logLocation, cfg = "", Config{}
+	}
+}
+
+func TestCompleteDescriptions(t *testing.T) {
+	defer completionLog(t, "Write docs", "Review PR", "write tests", "Write docs")()
+	cfg.Aliases = map[string]Alias{"review": {Description: "Code review"}, "standup": {Description: "Standup"}}
+
+	tests := []struct {
+		toComplete string
+		expected   []string
+	}{
+		{"@r", []string{"@review\tCode review"}},
+		{"@", []string{"@review\tCode review", "@standup\tStandup"}},
+		{"wr", []string{"Write docs", "write tests"}},
+		{"Rev", []string{"Review PR"}},
+		{"", []string{"@review\tCode review", "@standup\tStandup", "Write docs", "write tests", "Review PR"}},
+	}
+	for _, test := range tests {
+		got, _ := completeDescriptions(nil, nil, test.toComplete)
+		if strings.Join(got, "|") != strings.Join(test.expected, "|") {
+			t.Errorf("(%q) expected %q, got %q", test.toComplete, test.expected, got)
+		}
+	}
+
+	if got, _ := completeDescriptions(nil, []string{"Write docs"}, ""); len(got) != 0 {
+		t.Errorf("Expected no completions after the description, got %v", got)
+	}
+}
+
+func TestCompleteClients(t *testing.T) {
+	defer func() { cfg = Config{} }()
+	cfg.Clients = []track.Client{{Nickname: "acme", Name: "Acme Corp"}, {Nickname: "globex"}, {Nickname: "initech"}}
+
+	got, _ := completeClients(nil, nil, "")
+	if strings.Join(got, "|") != "acme\tAcme Corp|globex|initech" {
+		t.Errorf("Expected all clients, got %q", got)
+	}
+	got, _ = completeClients(nil, nil, "g")
+	if strings.Join(got, "|") != "globex" {
+		t.Errorf("Expected globex, got %q", got)
+	}
+}
+
+func TestCompleteEntryIDs(t *testing.T) {
+	descriptions := []string{}
+	for ix := 0; ix < maxCompletions+10; ix++ {
+		descriptions = append(descriptions, fmt.Sprintf("Task %d", ix))
+	}
+	defer completionLog(t, descriptions...)()
+
+	got, _ := completeEntryIDs(nil, nil, "")
+	if len(got) != maxCompletions || got[0] != fmt.Sprintf("%d\tTask %d", maxCompletions+10, maxCompletions+9) {
+		t.Errorf("Expected the %d most recent entries, got %d starting with %q", maxCompletions, len(got), got[0])
+	}
+	got, _ = completeEntryIDs(nil, nil, "5")
+	if strings.Join(got, "|") != "59\tTask 58|58\tTask 57|57\tTask 56|56\tTask 55|55\tTask 54|54\tTask 53|53\tTask 52|52\tTask 51|51\tTask 50|50\tTask 49|5\tTask 4" {
+		t.Errorf("Expected the IDs starting with 5, got %q", got)
+	}
+	if got, _ := completeDescriptions(nil, nil, "task"); len(got) != maxCompletions {
+		t.Errorf("Expected %d descriptions, got %d", maxCompletions, len(got))
+	}
+	if got, _ := completeOneEntryID(nil, []string{"1"}, ""); len(got) != 0 {
+		t.Errorf("Expected no completions after the first ID, got %v", got)
+	}
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// stdin is shared by all prompts so that buffered input isn't lost
+// between them.
+var stdin = bufio.NewReader(os.Stdin)
+
+// prompt asks the user a question and returns the trimmed answer.
+func prompt(question string) string {
+	fmt.Print(question)
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return ""
+	}
+	return strings.TrimSpace(answer)
+}
+
+// confirm asks the user a yes or no question. Anything other than "y" or
+// "yes" is treated as no.
+func confirm(question string) bool {
+	answer := strings.ToLower(prompt(fmt.Sprintf("%s [y/N]: ", question)))
+	return answer == "y" || answer == "yes"
+}
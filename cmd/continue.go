@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+// defaultContinueThreshold is the longest break that continue reopens an
+// entry after if the continueThreshold setting is not set.
+const defaultContinueThreshold = 15 * time.Minute
+
+var thresholdArg string
+
+// continueCmd represents the continue command
+var continueCmd = &cobra.Command{
+	Use:   "continue",
+	Short: "Reopen the most recent entry after a short break",
+	Long: `Reopen the most recent finished entry so that it is in progress again.
+The entry is only reopened if it finished at most the threshold ago, which
+is set with --threshold or the continueThreshold setting and defaults to
+15m. Use resume to start a new entry after a longer break.
+
+When several timers are used, choose the timer to continue with --timer
+or --client.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		threshold := cfg.ContinueThreshold
+		if threshold == 0 {
+			threshold = defaultContinueThreshold
+		}
+		if thresholdArg != "" {
+			var err error
+			threshold, err = timeexpr.ParseDuration(thresholdArg)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		tracker := GetTracker(oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		})
+		entry, err := track.Reopen(tracker.LoadEntries(), selectedTimer(), time.Now().UTC(), threshold)
+		if err != nil {
+			log.Fatal(err)
+		}
+		entry = tracker.SaveEntries([]track.Entry{entry})[0]
+
+		fmt.Println("Continuing entry", entry.ID)
+		fmt.Println()
+		fmt.Println(entry.StringIn(location))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(continueCmd)
+	continueCmd.Flags().StringVar(&timerArg, "timer", "", "Continue the most recent entry on this timer.")
+	continueCmd.Flags().StringVar(&clientFilterArg, "client", "", "Continue the most recent entry on the timer of the client with this nickname.")
+	registerClientCompletion(continueCmd, "client")
+	continueCmd.Flags().StringVar(&thresholdArg, "threshold", "", "Longest break to continue after (default is the continueThreshold setting or 15m).")
+}
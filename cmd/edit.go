@@ -5,20 +5,38 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/timeexpr"
 	"github.com/hdoupe/ttrack/track"
 	"github.com/spf13/cobra"
 )
 
-var agoArg string
+var (
+	agoArg       string
+	shiftArg     string
+	setClientArg string
+	replaceArg   string
+	withArg      string
+	interactive  bool
+	billArg      bool
+)
 
 // editCmd represents the edit command
 var editCmd = &cobra.Command{
-	Use:   "edit",
-	Short: "Edit the values of the most recent entry.",
-	Long:  `Edit start, finish, or description values.`,
+	Use:   "edit [description]",
+	Short: "Edit the values of one or more entries.",
+	Long: `Edit start, finish, or description values.
+
+By default, the most recent entry is edited. Entries can be selected by ID
+or external ID with --id, with a --query, or with the --since, --until,
+--client, --match and --regex filters. Changes to a selection of entries can shift their start
+times, reassign their client, or replace text in their descriptions.
+
+With --interactive, the selected entries (today's entries by default) are
+opened in $EDITOR where they can be changed, reordered, deleted or added.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 1 {
 			return errors.New("either no arguments or one argument must be set")
@@ -26,18 +44,6 @@ var editCmd = &cobra.Command{
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		var ago int
-
-		if agoArg != "" {
-			i, err := strconv.Atoi(agoArg)
-			if err != nil {
-				log.Fatal(err)
-			}
-			ago = i
-		} else {
-			ago = 1
-		}
-
 		client := oauth.Client{
 			ClientID:     cfg.ClientID,
 			ClientSecret: cfg.ClientSecret,
@@ -45,48 +51,175 @@ var editCmd = &cobra.Command{
 		tracker := GetTracker(client)
 
 		entries := tracker.LoadEntries()
-		if len(entries) < ago {
-			log.Fatal("There are only ", len(entries), "which is less than ago: ", ago, ".")
+		if interactive {
+			if len(args) > 0 || agoArg != "" || startedArg != "" || finishedArg != "" || durationArg != "" ||
+				shiftArg != "" || setClientArg != "" || replaceArg != "" || len(tagArgs) > 0 || billArg || noBillArg {
+				log.Fatal("interactive can only be used with the --id, --query, --since, --until, --client, --match, and --regex flags.")
+			}
+			editInteractively(tracker, entries)
+			return
 		}
-		entry := entries[len(entries)-ago]
 
-		if startedArg != "" {
-			t, err := ParseTimeArg(startedArg)
-			if err != nil {
-				log.Fatal(err)
+		var selected []track.Entry
+		if hasSelection() {
+			if agoArg != "" {
+				log.Fatal("ago can not be used with the --id, --query, --since, --until, --client, --match, or --regex flags.")
+			}
+			selected = selectEntries(entries)
+		} else {
+			ago := 1
+			if agoArg != "" {
+				i, err := strconv.Atoi(agoArg)
+				if err != nil {
+					log.Fatal(err)
+				}
+				ago = i
 			}
-			entry.StartedAt = t
+			if len(entries) < ago {
+				log.Fatal("There are only ", len(entries), "which is less than ago: ", ago, ".")
+			}
+			selected = []track.Entry{entries[len(entries)-ago]}
+		}
+
+		if len(selected) == 0 {
+			fmt.Println("No entries matched the query parameters.")
+			return
+		}
+		if len(selected) > 1 && (startedArg != "" || finishedArg != "" || durationArg != "") {
+			log.Fatal("started-at, finished-at, and duration can only be used when editing one entry.")
 		}
 		if finishedArg != "" && durationArg != "" {
 			log.Fatal("Only one of finished-at and duration can be specified.")
 		}
-		if finishedArg != "" {
-			t, err := ParseTimeArg(finishedArg)
-			if err != nil {
-				log.Fatal(err)
+		if withArg != "" && replaceArg == "" {
+			log.Fatal("--with must be used with --replace.")
+		}
+		if billArg && noBillArg {
+			log.Fatal("Only one of --bill and --no-bill can be specified.")
+		}
+
+		changed := []track.Entry{}
+		for _, entry := range selected {
+			edited := editEntry(entry, args)
+			if fields := track.Diff(entry, edited); len(fields) > 0 {
+				fmt.Printf("ID: %v (%s)\n", entry.ID, entry.Description)
+				for _, field := range fields {
+					fmt.Printf("  %s: %q -> %q\n", field.Field, field.Old, field.New)
+				}
+				fmt.Println()
+				changed = append(changed, edited)
 			}
-			entry.FinishedAt = t
-			entry.Duration = int(t.Sub(entry.StartedAt).Seconds())
 		}
-		if durationArg != "" {
-			d, err := time.ParseDuration(durationArg)
-			if err != nil {
+
+		if len(changed) == 0 {
+			fmt.Println("No changes to save.")
+			return
+		}
+		if !yesArg && !confirm(fmt.Sprintf("Save changes to %d entries?", len(changed))) {
+			fmt.Println("No entries were changed.")
+			return
+		}
+
+		tracker.SaveEntries(changed)
+		fmt.Println("Updated", len(changed), "entries.")
+	},
+}
+
+// editEntry applies the changes from the edit flags and arguments.
+func editEntry(entry track.Entry, args []string) track.Entry {
+	if startedArg != "" {
+		t, err := ParseTimeArg(startedArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		entry.StartedAt = t
+	}
+	if finishedArg != "" {
+		t, err := ParseTimeArg(finishedArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		entry.FinishedAt = t
+		entry.Duration = int((t.Sub(entry.StartedAt) - entry.BreakTime()).Seconds())
+	}
+	if durationArg != "" {
+		d, err := timeexpr.ParseDuration(durationArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if d > 0 {
+			if err := entry.End(d, time.Time{}); err != nil {
 				log.Fatal(err)
 			}
+		} else {
 			entry.Duration = int(d.Seconds())
-			entry.FinishedAt = entry.StartedAt.Add(d).UTC()
+			entry.FinishedAt = entry.StartedAt.Add(d + entry.BreakTime()).UTC()
 		}
-		if len(args) == 1 {
-			entry.Description = args[0]
+	}
+	if shiftArg != "" {
+		d, err := timeexpr.ParseDuration(shiftArg)
+		if err != nil {
+			log.Fatal(err)
 		}
-
-		fmt.Println()
-		fmt.Println(entry.String())
-		tracker.SaveEntries([]track.Entry{entry})
-	},
+		entry.StartedAt = entry.StartedAt.Add(d)
+		if !entry.FinishedAt.IsZero() {
+			entry.FinishedAt = entry.FinishedAt.Add(d)
+		}
+		breaks := make([]track.Break, len(entry.Breaks))
+		for ix, b := range entry.Breaks {
+			breaks[ix].StartedAt = b.StartedAt.Add(d)
+			if !b.FinishedAt.IsZero() {
+				breaks[ix].FinishedAt = b.FinishedAt.Add(d)
+			}
+		}
+		entry.Breaks = breaks
+	}
+	if setClientArg != "" {
+		client := lookupClient(setClientArg)
+		entry.ClientID = client.ClientID
+		entry.ProjectID = client.ProjectID
+	}
+	if replaceArg != "" {
+		entry.Description = strings.ReplaceAll(entry.Description, replaceArg, withArg)
+	}
+	if len(tagArgs) > 0 {
+		entry.Tags = []string{}
+		entry.AddTags(tagArgs...)
+	}
+	if billArg {
+		entry.Billable = true
+	}
+	if noBillArg {
+		entry.Billable = false
+	}
+	if len(args) == 1 {
+		entry.Description = args[0]
+	}
+	return entry
 }
 
 func init() {
 	rootCmd.AddCommand(editCmd)
 	editCmd.Flags().StringVarP(&agoArg, "ago", "a", "", "Edit ago-th most recent entry.")
+	editCmd.Flags().IntSliceVar(&idArgs, "id", []int{}, "Edit entries by ID or external ID.")
+	editCmd.Flags().StringVar(&sinceArg, "since", "", "Edit entries starting from some date.")
+	editCmd.Flags().StringVar(&untilArg, "until", "", "Edit entries until some date.")
+	editCmd.Flags().StringVar(&clientFilterArg, "client", "", "Edit entries for the client with this nickname.")
+	editCmd.Flags().StringVarP(&queryArg, "query", "q", "", "Edit entries matching a query (eg. -q 'tag:meeting and started>=2021-03-01').")
+	editCmd.Flags().StringVar(&matchArg, "match", "", "Edit entries with descriptions containing this text.")
+	editCmd.Flags().StringVar(&regexArg, "regex", "", "Edit entries with descriptions matching this regular expression.")
+	editCmd.Flags().StringVar(&shiftArg, "shift", "", "Shift start and finish times (eg. --shift -15m).")
+	editCmd.Flags().StringVar(&setClientArg, "set-client", "", "Assign entries to the client with this nickname.")
+	editCmd.Flags().StringVar(&replaceArg, "replace", "", "Replace this text in descriptions (use with --with).")
+	editCmd.Flags().StringVar(&withArg, "with", "", "Replacement text for --replace.")
+	editCmd.Flags().StringSliceVarP(&tagArgs, "tag", "t", []string{}, "Replace the tags of entries (eg. -t meeting -t review).")
+	editCmd.Flags().BoolVar(&billArg, "bill", false, "Mark entries as billable.")
+	editCmd.Flags().BoolVar(&noBillArg, "no-bill", false, "Mark entries as not billable.")
+	editCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Edit entries in $EDITOR.")
+	editCmd.Flags().BoolVarP(&yesArg, "yes", "y", false, "Save changes without asking for confirmation.")
+	if err := editCmd.RegisterFlagCompletionFunc("id", completeEntryIDs); err != nil {
+		log.Fatal(err)
+	}
+	registerClientCompletion(editCmd, "client")
+	registerClientCompletion(editCmd, "set-client")
 }
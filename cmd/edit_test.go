@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+// resetEditFlags clears the selection and edit flags after a test.
+func resetEditFlags() {
+	idArgs, queryArg, matchArg, regexArg, clientFilterArg = nil, "", "", "", ""
+	sinceArg, untilArg, periodArg, minArg, maxArg = "", "", "", "", ""
+	shiftArg, setClientArg, replaceArg, withArg = "", "", "", ""
+	tagArgs, billArg, noBillArg = nil, false, false
+	cfg = Config{}
+	location = time.Local
+}
+
+func editEntries() []track.Entry {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	return []track.Entry{
+		{ID: 1, ExternalID: 101, Description: "Review PR", ClientID: 1, StartedAt: started, FinishedAt: started.Add(time.Hour), Duration: 3600, Billable: true},
+		{ID: 2, ExternalID: 102, Description: "Write docs", ClientID: 2, StartedAt: started.Add(time.Hour), FinishedAt: started.Add(2 * time.Hour), Duration: 3600, Billable: true},
+		{ID: 3, Description: "Review docs", ClientID: 1, StartedAt: started.Add(2 * time.Hour), FinishedAt: started.Add(150 * time.Minute), Duration: 1800, Tags: []string{"meeting"}},
+	}
+}
+
+func TestSelectEntries(t *testing.T) {
+	defer resetEditFlags()
+	cfg.Clients = []track.Client{{Nickname: "acme", ClientID: 1}, {Nickname: "globex", ClientID: 2}}
+
+	ids := func(entries []track.Entry) []int {
+		res := []int{}
+		for _, entry := range entries {
+			res = append(res, entry.ID)
+		}
+		return res
+	}
+	tests := []struct {
+		name  string
+		setup func()
+		ids   []int
+	}{
+		{"ID and external ID", func() { idArgs = []int{3, 102} }, []int{2, 3}},
+		{"same entry twice", func() { idArgs = []int{1, 101, 1} }, []int{1}},
+		{"IDs and query", func() { idArgs = []int{1, 3}; queryArg = "tag:meeting" }, []int{3}},
+		{"match", func() { matchArg = "review" }, []int{1, 3}},
+		{"client", func() { clientFilterArg = "globex" }, []int{2}},
+		{"query", func() { queryArg = "tag:meeting" }, []int{3}},
+		{"IDs and match", func() { idArgs = []int{1, 2}; matchArg = "review" }, []int{1}},
+		{"client and regex", func() { clientFilterArg = "acme"; regexArg = "docs$" }, []int{3}},
+		{"since", func() { sinceArg = "2021-03-30T10:30:00Z" }, []int{3}},
+		{"minimum duration", func() { minArg = "45m" }, []int{1, 2}},
+	}
+	for _, test := range tests {
+		idArgs, queryArg, matchArg, regexArg, clientFilterArg, sinceArg, minArg = nil, "", "", "", "", "", ""
+		test.setup()
+		got := ids(selectEntries(editEntries()))
+		if len(got) != len(test.ids) {
+			t.Errorf("(%s) expected %v, got %v", test.name, test.ids, got)
+			continue
+		}
+		for ix := range got {
+			if got[ix] != test.ids[ix] {
+				t.Errorf("(%s) expected %v, got %v", test.name, test.ids, got)
+				break
+			}
+		}
+	}
+}
+
+func TestEditEntryBulkChanges(t *testing.T) {
+	defer resetEditFlags()
+	cfg.Clients = []track.Client{{Nickname: "acme", ClientID: 1}, {Nickname: "globex", ClientID: 2, ProjectID: 7}}
+
+	entry := editEntries()[2]
+	entry.Breaks = []track.Break{{StartedAt: entry.StartedAt.Add(10 * time.Minute), FinishedAt: entry.StartedAt.Add(15 * time.Minute)}}
+
+	shiftArg = "-15m"
+	setClientArg = "globex"
+	replaceArg, withArg = "docs", "notes"
+	tagArgs = []string{"review"}
+	billArg = true
+	edited := editEntry(entry, nil)
+
+	if !edited.StartedAt.Equal(entry.StartedAt.Add(-15*time.Minute)) || !edited.FinishedAt.Equal(entry.FinishedAt.Add(-15*time.Minute)) {
+		t.Errorf("Expected the entry to be shifted by 15 minutes, got %v to %v", edited.StartedAt, edited.FinishedAt)
+	}
+	if !edited.Breaks[0].StartedAt.Equal(entry.Breaks[0].StartedAt.Add(-15*time.Minute)) || !entry.Breaks[0].StartedAt.Equal(entry.StartedAt.Add(10*time.Minute)) {
+		t.Errorf("Expected only the edited break to be shifted, got %v and %v", edited.Breaks, entry.Breaks)
+	}
+	if edited.Duration != entry.Duration {
+		t.Errorf("Expected the duration to be kept, got %v", edited.Duration)
+	}
+	if edited.ClientID != 2 || edited.ProjectID != 7 {
+		t.Errorf("Expected the entry to be assigned to globex, got client %v and project %v", edited.ClientID, edited.ProjectID)
+	}
+	if edited.Description != "Review notes" {
+		t.Errorf("Expected the description to be replaced, got %q", edited.Description)
+	}
+	if len(edited.Tags) != 1 || edited.Tags[0] != "review" || !edited.Billable {
+		t.Errorf("Expected the tags to be replaced and the entry to be billable, got %v and %v", edited.Tags, edited.Billable)
+	}
+
+	fields := track.Diff(entry, edited)
+	if len(fields) != 8 {
+		t.Errorf("Expected 8 changed fields, got %v", fields)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/timeexpr"
 	"github.com/hdoupe/ttrack/track"
 	"github.com/spf13/cobra"
 )
@@ -15,7 +16,10 @@ import (
 var finishCmd = &cobra.Command{
 	Use:   "finish",
 	Short: "Finish an entry",
-	Long:  `Finish a previously started entry.`,
+	Long: `Finish a previously started entry.
+
+When several timers are running, choose the one to finish with --timer
+or --client.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 1 {
 			return errors.New("either no arguments or one argument must be set")
@@ -35,7 +39,7 @@ var finishCmd = &cobra.Command{
 		var duration time.Duration
 		if durationArg != "" {
 			var err error
-			duration, err = time.ParseDuration(durationArg)
+			duration, err = timeexpr.ParseDuration(durationArg)
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -51,10 +55,13 @@ var finishCmd = &cobra.Command{
 		}
 
 		entry := track.Entry{
+			Timer:       selectedTimer(),
 			StartedAt:   startedAt,
 			FinishedAt:  finishedAt,
 			Description: description,
 			Duration:    int(duration.Seconds()),
+			Tags:        tagArgs,
+			Billable:    !noBillArg,
 		}
 
 		client := oauth.Client{
@@ -65,10 +72,16 @@ var finishCmd = &cobra.Command{
 		entry = tracker.Finish(entry)
 
 		fmt.Println()
-		fmt.Println(entry.String())
+		fmt.Println(entry.StringIn(location))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(finishCmd)
+	finishCmd.Flags().StringSliceVarP(&tagArgs, "tag", "t", []string{}, "Add tags to the entry (eg. -t meeting -t review).")
+	finishCmd.Flags().BoolVar(&noBillArg, "no-bill", false, "Mark the entry as not billable.")
+	finishCmd.Flags().StringVar(&clientFilterArg, "client", "", "Finish the timer of the client with this nickname.")
+	finishCmd.Flags().StringVar(&timerArg, "timer", "", "Finish the entry on this timer.")
+	finishCmd.ValidArgsFunction = completeDescriptions
+	registerClientCompletion(finishCmd, "client")
 }
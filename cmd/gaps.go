@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+var (
+	minGapArg string
+	fillArg   bool
+)
+
+// gapsCmd represents the gaps command
+var gapsCmd = &cobra.Command{
+	Use:   "gaps",
+	Short: "List untracked working time.",
+	Long: `List the periods of working time that do not have any entries.
+
+Working hours are read from the workHours setting in the config file, e.g.
+
+  workHours:
+    monday: 09:00-12:00,13:00-17:00
+    tuesday: 09:00-17:00
+
+and default to 09:00-17:00, Monday through Friday. Gaps are listed from
+the start of the week by default. Use --fill to add an entry for each gap
+or extend the entries next to it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		hours := track.DefaultWorkHours()
+		if len(cfg.WorkHours) > 0 {
+			var err error
+			hours, err = track.ParseWorkHours(cfg.WorkHours)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		now := time.Now().In(location)
+		since := startOfWeek(now)
+		until := now
+		if sinceArg != "" {
+			t, err := ParseTimeArg(sinceArg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			since = t
+		}
+		if untilArg != "" {
+			t, err := ParseTimeArg(untilArg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			until = t
+		}
+		minGap, err := timeexpr.ParseDuration(minGapArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		client := oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		}
+		tracker := GetTracker(client)
+		entries := tracker.LoadEntries()
+
+		gaps := track.Gaps(entries, hours, since, until, location, minGap)
+		if len(gaps) == 0 {
+			fmt.Println("No gaps found.")
+			return
+		}
+
+		var total time.Duration
+		for _, gap := range gaps {
+			fmt.Println(gap.StringIn(location))
+			total += gap.Duration()
+		}
+		fmt.Println()
+		fmt.Println("Total untracked time: ", total.Round(time.Minute))
+
+		if fillArg {
+			fillGaps(tracker, gaps)
+		}
+	},
+}
+
+// entryKey identifies entries that may not have been saved locally yet.
+type entryKey struct {
+	ID         int
+	ExternalID int
+}
+
+// fillGaps asks the user how to fill each gap and saves the new and
+// extended entries.
+func fillGaps(tracker track.Tracker, gaps []track.Gap) {
+	changed := map[entryKey]track.Entry{}
+	latest := func(entry track.Entry) track.Entry {
+		if updated, ok := changed[entryKey{entry.ID, entry.ExternalID}]; ok {
+			return updated
+		}
+		return entry
+	}
+	added := []track.Entry{}
+
+gapLoop:
+	for _, gap := range gaps {
+		fmt.Println()
+		fmt.Println(gap.StringIn(location))
+		options := []string{"[n]ew entry"}
+		before := latest(gap.Before)
+		after := latest(gap.After)
+		canExtendBefore := !before.IsZero() && !before.InProgress() && before.FinishedAt.Equal(gap.Start)
+		canExtendAfter := !after.IsZero() && after.StartedAt.Equal(gap.End)
+		if canExtendBefore {
+			options = append(options, fmt.Sprintf("extend [p]revious (%s)", before.Description))
+		}
+		if canExtendAfter {
+			options = append(options, fmt.Sprintf("extend ne[x]t (%s)", after.Description))
+		}
+		options = append(options, "[s]kip", "[q]uit")
+
+		answer := strings.ToLower(prompt(strings.Join(options, ", ") + ": "))
+		switch {
+		case answer == "n":
+			description := prompt("Description: ")
+			entry := track.Entry{
+				StartedAt:   gap.Start.UTC(),
+				Description: description,
+				ClientID:    cfg.CurrentClient.ClientID,
+				ProjectID:   cfg.CurrentClient.ProjectID,
+				Billable:    true,
+			}
+			if err := entry.End(0, gap.End.UTC()); err != nil {
+				log.Fatal(err)
+			}
+			added = append(added, entry)
+		case answer == "p" && canExtendBefore:
+			if err := before.End(0, gap.End.UTC()); err != nil {
+				log.Fatal(err)
+			}
+			changed[entryKey{before.ID, before.ExternalID}] = before
+		case answer == "x" && canExtendAfter:
+			finishedAt := after.FinishedAt
+			after.StartedAt = gap.Start.UTC()
+			if !after.InProgress() {
+				if err := after.End(0, finishedAt); err != nil {
+					log.Fatal(err)
+				}
+			}
+			changed[entryKey{after.ID, after.ExternalID}] = after
+		case answer == "q":
+			break gapLoop
+		}
+	}
+
+	toSave := added
+	for _, entry := range changed {
+		toSave = append(toSave, entry)
+	}
+	if len(toSave) == 0 {
+		fmt.Println("No changes to save.")
+		return
+	}
+	tracker.SaveEntries(toSave)
+	fmt.Println("Saved", len(toSave), "entries.")
+}
+
+func init() {
+	rootCmd.AddCommand(gapsCmd)
+	gapsCmd.Flags().StringVar(&sinceArg, "since", "", "Find gaps starting from some date (default is the start of the week).")
+	gapsCmd.Flags().StringVar(&untilArg, "until", "", "Find gaps until some date (default is now).")
+	gapsCmd.Flags().StringVar(&minGapArg, "min", "15m", "Skip gaps shorter than this duration.")
+	gapsCmd.Flags().BoolVar(&fillArg, "fill", false, "Fill each gap with a new entry or by extending an adjacent entry.")
+}
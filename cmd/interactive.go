@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+)
+
+// editor returns the command for the user's preferred editor.
+func editor() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// runEditor opens the file in the user's editor and waits for it to
+// close.
+func runEditor(path string) error {
+	args := append(editor(), path)
+	// nolint: gosec
+	editorCmd := exec.Command(args[0], args[1:]...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	return editorCmd.Run()
+}
+
+// editInteractively writes the selected entries to a temporary file,
+// opens it in the user's editor, and saves the changes. Today's entries
+// are used if no entries are selected.
+func editInteractively(tracker track.Tracker, entries []track.Entry) {
+	var selected []track.Entry
+	if hasSelection() {
+		selected = selectEntries(entries)
+	} else {
+		today := timeexpr.StartOfDay(time.Now().In(location))
+		selected = track.FilterEntries(entries, track.FilterParameters{Since: today})
+	}
+
+	file, err := ioutil.TempFile("", "ttrack-*.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(track.FormatSheet(selected, cfg.Clients, location)); err != nil {
+		log.Fatal(err)
+	}
+	file.Close()
+
+	var edited []track.Entry
+	for {
+		if err := runEditor(file.Name()); err != nil {
+			log.Fatal("Unable to run editor: ", err)
+		}
+		content, err := ioutil.ReadFile(file.Name())
+		if err != nil {
+			log.Fatal(err)
+		}
+		edited, err = track.ParseSheet(string(content), selected, cfg.Clients, location)
+		if err == nil {
+			break
+		}
+		fmt.Println(err)
+		if !confirm("Edit the entries again?") {
+			fmt.Println("No entries were changed.")
+			return
+		}
+	}
+
+	kept := map[int]bool{}
+	changed := []track.Entry{}
+	for _, entry := range edited {
+		if entry.ID == 0 {
+			fmt.Println("New entry:")
+			fmt.Println(entry.StringIn(location))
+			fmt.Println()
+			changed = append(changed, entry)
+			continue
+		}
+		kept[entry.ID] = true
+		orig, _ := track.FindEntry(selected, entry.ID)
+		if fields := track.Diff(orig, entry); len(fields) > 0 {
+			fmt.Printf("ID: %v (%s)\n", entry.ID, orig.Description)
+			for _, field := range fields {
+				fmt.Printf("  %s: %q -> %q\n", field.Field, field.Old, field.New)
+			}
+			fmt.Println()
+			changed = append(changed, entry)
+		}
+	}
+	deleted := []track.Entry{}
+	for _, entry := range selected {
+		if !kept[entry.ID] {
+			fmt.Printf("Delete ID: %v (%s)\n\n", entry.ID, entry.Description)
+			deleted = append(deleted, entry)
+		}
+	}
+
+	if len(changed) == 0 && len(deleted) == 0 {
+		fmt.Println("No changes to save.")
+		return
+	}
+	question := fmt.Sprintf("Save %d changed or new entries and delete %d entries?", len(changed), len(deleted))
+	if !yesArg && !confirm(question) {
+		fmt.Println("No entries were changed.")
+		return
+	}
+	if len(changed) > 0 {
+		tracker.SaveEntries(changed)
+	}
+	if len(deleted) > 0 {
+		tracker.Delete(deleted)
+	}
+	fmt.Println("Saved", len(changed), "entries and deleted", len(deleted), "entries.")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/output"
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lineByArg string
+	dryRunArg bool
+)
+
+// invoiceCmd represents the invoice command
+var invoiceCmd = &cobra.Command{
+	Use:   "invoice",
+	Short: "Manage invoices",
+}
+
+var createInvoiceCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a draft invoice on FreshBooks from tracked time.",
+	Long: `Create a draft invoice on FreshBooks for the billable entries of a client
+that have not been invoiced yet, e.g.
+
+  ttrack invoice create --client acme --since 2021-03-01 --until 2021-03-31
+
+Entries are grouped into line items by day, tag or description. Line
+items use the hourly rates from the rates setting and durations rounded
+with the rounding setting. The entries on the invoice are marked with the
+invoice ID so that they are never invoiced twice. Use --dry-run to
+preview the invoice without creating it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if clientFilterArg == "" {
+			log.Fatal("Use --client to choose the client to invoice.")
+		}
+		client := lookupClient(clientFilterArg)
+
+		tracker := GetTracker(oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		})
+		fb, ok := tracker.(*track.FreshBooks)
+		if !ok && !dryRunArg {
+			log.Fatal("Use 'ttrack connect' to log in to Freshbooks")
+		}
+		entries := track.FilterEntries(applyQuery(tracker.LoadEntries()), filterParameters())
+
+		invoice, err := track.NewInvoice(entries, track.InvoiceOptions{
+			ClientID: client.ClientID,
+			By:       lineByArg,
+			Rates:    rates(),
+			Clients:  cfg.Clients,
+			Rounding: roundingPolicy(),
+			Location: location,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(invoice.Lines) == 0 {
+			fmt.Println("No billable entries to invoice.")
+			return
+		}
+		writeInvoice(invoice)
+		if dryRunArg {
+			return
+		}
+
+		if !yesArg && !confirm(fmt.Sprintf("Create a draft invoice for %s?", client.Nickname)) {
+			fmt.Println("No invoice created.")
+			return
+		}
+		invoiceID := fb.CreateInvoice(invoice)
+		fb.MarkInvoiced(invoice.Entries(), invoiceID)
+		fmt.Printf("Created draft invoice %d with %d entries.\n", invoiceID, len(invoice.Entries()))
+	},
+}
+
+// writeInvoice prints the line items of an invoice.
+func writeInvoice(invoice track.Invoice) {
+	header := []string{"Item", "Description", "Hours", "Rate", "Amount"}
+	rows := [][]string{}
+	for _, item := range invoice.Lines {
+		rows = append(rows, []string{
+			item.Name,
+			item.Description,
+			fmt.Sprintf("%.2f", item.Hours),
+			fmt.Sprintf("%.2f", item.Rate.Hourly),
+			fmt.Sprintf("%.2f", item.Amount()),
+		})
+	}
+	rows = append(rows, []string{"Total", "", "", "", strings.TrimSpace(fmt.Sprintf("%.2f %s", invoice.Total(), invoice.Currency))})
+	if err := output.WriteRows(os.Stdout, output.Table, header, rows); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(invoiceCmd)
+	invoiceCmd.AddCommand(createInvoiceCmd)
+
+	createInvoiceCmd.Flags().StringVar(&clientFilterArg, "client", "", "Invoice the client with this nickname.")
+	registerClientCompletion(createInvoiceCmd, "client")
+	createInvoiceCmd.Flags().StringVar(&sinceArg, "since", "", "Invoice entries starting from some date.")
+	createInvoiceCmd.Flags().StringVar(&periodArg, "period", "", "Invoice entries in a calendar period: "+strings.Join(timeexpr.Periods, ", ")+".")
+	createInvoiceCmd.Flags().StringVar(&untilArg, "until", "", "Invoice entries until some date.")
+	createInvoiceCmd.Flags().StringVarP(&queryArg, "query", "q", "", "Invoice entries matching a query (eg. -q 'tag:dev').")
+	createInvoiceCmd.Flags().StringVar(&lineByArg, "by", track.LineByDay, "Group entries into line items by one of: "+strings.Join(track.LineItemGroupings, ", ")+".")
+	createInvoiceCmd.Flags().BoolVar(&dryRunArg, "dry-run", false, "Preview the invoice without creating it.")
+	createInvoiceCmd.Flags().BoolVarP(&yesArg, "yes", "y", false, "Create the invoice without asking for confirmation.")
+}
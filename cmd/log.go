@@ -3,55 +3,59 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/output"
+	"github.com/hdoupe/ttrack/timeexpr"
 	"github.com/hdoupe/ttrack/track"
 	"github.com/spf13/cobra"
 )
 
 var (
-	lastArg  string
-	sinceArg string
-	untilArg string
-	limitArg string
+	lastArg     string
+	sinceArg    string
+	untilArg    string
+	limitArg    string
+	billableArg bool
+	outputArg   string
+	formatArg   string
 )
 
 // logCmd represents the log command
 var logCmd = &cobra.Command{
 	Use:   "log",
 	Short: "View time entry log.",
-	Long:  `View time entries by querying time periods or description substrings.`,
+	Long: `View time entries by querying time periods, description substrings or
+regular expressions, clients, projects, tags, and durations. All of the
+filters must match.
+
+Entries can also be selected with a query, e.g.
+
+  ttrack log -q 'client:acme and tag:meeting and started>=2021-03-01 and duration>30m and desc~"deploy"'
+
+Use --output to write a table, JSON, JSONL, CSV, TSV or Markdown, or
+--format to write each entry with a Go template. Template functions are
+duration, hours, rounded, time, client and join, e.g.
+
+  ttrack log --format '{{time "2006-01-02" .StartedAt}},{{hours . | printf "%.2f"}},{{.Description}}'`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
-			last  time.Duration
+			last  timeexpr.Span
 			since time.Time
-			until time.Time
 			limit int
 			err   error
 		)
 
 		if lastArg != "" {
-			last, err = time.ParseDuration(lastArg)
-			if err != nil {
-				log.Fatal(err)
-			}
-			since = time.Now().UTC().Add(-last)
-		}
-
-		if sinceArg != "" {
-			since, err = ParseTimeArg(sinceArg)
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
-
-		if untilArg != "" {
-			until, err = ParseTimeArg(untilArg)
+			last, err = timeexpr.ParseSpan(lastArg)
 			if err != nil {
 				log.Fatal(err)
 			}
+			since = last.Neg().Add(time.Now().In(location)).UTC()
 		}
 
 		if limitArg != "" {
@@ -67,16 +71,28 @@ var logCmd = &cobra.Command{
 		}
 		tracker := GetTracker(client)
 
-		entries := tracker.LoadEntries()
+		entries := applyQuery(tracker.LoadEntries())
 
-		params := track.FilterParameters{
-			Since: since,
-			Until: until,
-			Limit: limit,
+		params := filterParameters()
+		if params.Since.IsZero() {
+			params.Since = since
+		}
+		params.Limit = limit
+		if billableArg && noBillArg {
+			log.Fatal("Only one of --billable and --no-bill can be specified.")
+		}
+		if billableArg || noBillArg {
+			billable := billableArg
+			params.Billable = &billable
 		}
 
 		entries = track.FilterEntries(entries, params)
 
+		if outputArg != "" || formatArg != "" {
+			writeEntries(entries)
+			return
+		}
+
 		if len(entries) == 0 {
 			fmt.Println("No entries matched the query parameters.")
 			return
@@ -85,7 +101,7 @@ var logCmd = &cobra.Command{
 		var total time.Duration
 
 		for _, entry := range entries {
-			fmt.Println(entry.String())
+			fmt.Println(entry.StringIn(location))
 			fmt.Println()
 			d, _ := entry.GetDuration()
 			total = total + d
@@ -95,12 +111,48 @@ var logCmd = &cobra.Command{
 	},
 }
 
+// writeEntries writes entries using the --output or --format flag.
+func writeEntries(entries []track.Entry) {
+	if outputArg != "" && formatArg != "" {
+		log.Fatal("Only one of --output and --format can be specified.")
+	}
+	writer := output.Writer{Location: location, Clients: cfg.Clients, Rounding: roundingPolicy()}
+	if formatArg != "" {
+		tmpl, err := writer.Template(formatArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := writer.WriteTemplate(os.Stdout, entries, tmpl); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := writer.Write(os.Stdout, entries, outputArg); err != nil {
+		log.Fatal(err)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(logCmd)
 
 	// Here you will define your flags and configuration settings.
-	logCmd.Flags().StringVarP(&lastArg, "last", "l", "", "Show entries over previous time period (eg. --last 1w).")
+	logCmd.Flags().StringVarP(&lastArg, "last", "l", "", "Show entries over previous time period (eg. --last 1w, --last 1mo, --last 36h).")
+	logCmd.Flags().StringVar(&periodArg, "period", "", "Show entries in a calendar period: "+strings.Join(timeexpr.Periods, ", ")+".")
 	logCmd.Flags().StringVarP(&limitArg, "limit", "n", "", "Show entries over previous time period (eg. --last 1w).")
 	logCmd.Flags().StringVar(&sinceArg, "since", "", "Show entries starting from some date.")
 	logCmd.Flags().StringVar(&untilArg, "until", "", "Show entries until some date.")
+	logCmd.Flags().StringSliceVarP(&tagArgs, "tag", "t", []string{}, "Show entries with all of these tags.")
+	logCmd.Flags().StringVarP(&outputArg, "output", "o", "", "Output format: "+strings.Join(output.Formats, ", ")+".")
+	logCmd.Flags().StringVar(&formatArg, "format", "", "Go template for each entry (eg. --format '{{.ID}} {{duration .}} {{.Description}}').")
+	logCmd.Flags().StringVarP(&queryArg, "query", "q", "", "Show entries matching a query (eg. -q 'tag:meeting and duration>30m').")
+	logCmd.Flags().StringVar(&matchArg, "match", "", "Show entries with descriptions containing this text.")
+	logCmd.Flags().BoolVar(&caseSensitiveArg, "case-sensitive", false, "Match descriptions with --match case sensitively.")
+	logCmd.Flags().StringVar(&regexArg, "regex", "", "Show entries with descriptions matching this regular expression.")
+	logCmd.Flags().StringVar(&clientFilterArg, "client", "", "Show entries for the client with this nickname.")
+	registerClientCompletion(logCmd, "client")
+	logCmd.Flags().StringVar(&projectFilterArg, "project", "", "Show entries for the project with this ID.")
+	logCmd.Flags().StringVar(&minArg, "min", "", "Show entries that are at least this long (eg. --min 30m).")
+	logCmd.Flags().StringVar(&maxArg, "max", "", "Show entries that are at most this long (eg. --max 2h).")
+	logCmd.Flags().BoolVar(&billableArg, "billable", false, "Show billable entries.")
+	logCmd.Flags().BoolVar(&noBillArg, "no-bill", false, "Show entries that are not billable.")
 }
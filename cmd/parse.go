@@ -1,50 +1,55 @@
 package cmd
 
 import (
-	"errors"
-	"fmt"
+	"log"
 	"time"
+
+	"github.com/hdoupe/ttrack/timeexpr"
 )
 
-// ParseTimeArg converts a string into a time.Time object.
+// ParseTimeArg converts a time expression such as "yesterday 3pm",
+// "mon 9:30", "-15m" or "2021-03-30T15:04-04:00" into a time.Time object
+// in UTC. Expressions without an offset are in the configured timezone.
 func ParseTimeArg(arg string) (time.Time, error) {
-	dateFmts := []string{
-		"2006-01-02 3:04:05 PM MST",
-		"01-02 3:04:05 PM MST",
-		"2006-01-02 3:04 PM MST",
-		"01-02 3:04 PM MST",
-		time.Kitchen,
-		"2006-01-02",
+	parser := timeexpr.Parser{Location: location}
+	t, err := parser.Parse(arg)
+	if err != nil {
+		return time.Time{}, err
 	}
-	var res time.Time
-	for _, dateFmt := range dateFmts {
-		// Special update for kitchen time format to include year, month,
-		// and day.
-		if dateFmt == time.Kitchen {
-			loc, _ := time.LoadLocation("Local")
-			t, err := time.ParseInLocation(dateFmt, arg, loc)
-			if err != nil {
-				continue
-			}
-			res = t
-			n := time.Now()
-			// Month and day default values are already 1!
-			res = res.AddDate(n.Year(), int(n.Month())-1, n.Day()-1)
-			break
-		} else {
-			t, err := time.Parse(dateFmt, arg)
-			if err == nil {
-				res = t
-				break
-			}
-		}
+	return t.UTC(), nil
+}
+
+// loadLocation returns the timezone named by --tz or the timezone setting,
+// or the system timezone if neither is set.
+func loadLocation() *time.Location {
+	name, source := tzArg, "--tz"
+	if name == "" {
+		name, source = cfg.Timezone, "timezone config"
 	}
-	if res.IsZero() {
-		msg := fmt.Sprintf("Unable to parse %s using formats %v", arg, dateFmts)
-		return res, errors.New(msg)
+	if name == "" {
+		return time.Local
 	}
-	if res.Year() == 0 {
-		res = res.AddDate(time.Now().In(res.Location()).Year(), 0, 0)
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", source, err)
 	}
-	return res.UTC(), nil
+	return loc
+}
+
+// weekStart returns the configured first day of the week. Weeks start on
+// Monday by default.
+func weekStart() time.Weekday {
+	if cfg.WeekStart == "" {
+		return time.Monday
+	}
+	weekday, err := timeexpr.ParseWeekday(cfg.WeekStart)
+	if err != nil {
+		log.Fatal("Invalid weekStart config: ", err)
+	}
+	return weekday
+}
+
+// startOfWeek returns midnight at the start of the week containing t.
+func startOfWeek(t time.Time) time.Time {
+	return timeexpr.StartOfWeek(t.In(location), weekStart())
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadLocation(t *testing.T) {
+	defer func() { tzArg, cfg = "", Config{} }()
+
+	tests := []struct {
+		tz       string
+		timezone string
+		name     string
+	}{
+		{"", "", time.Local.String()},
+		{"", "Europe/Berlin", "Europe/Berlin"},
+		{"America/New_York", "Europe/Berlin", "America/New_York"},
+	}
+	for _, test := range tests {
+		tzArg, cfg.Timezone = test.tz, test.timezone
+		if got := loadLocation().String(); got != test.name {
+			t.Errorf("(--tz %q, timezone %q) expected %s, got %s", test.tz, test.timezone, test.name, got)
+		}
+	}
+}
+
+func TestParseTimeArgInLocation(t *testing.T) {
+	defer func() { location = time.Local }()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	location = loc
+	got, err := ParseTimeArg("2021-03-30 22:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 3, 31, 2, 30, 0, 0, time.UTC); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
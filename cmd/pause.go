@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+var atArg string
+
+// pauseCmd represents the pause command
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Start a break in the running entry",
+	Long: `Start a break in the entry in progress. Breaks are not counted in the
+duration of the entry. Use unpause to finish the break, or finish to
+finish the entry and the break together.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		updateBreak(func(entry *track.Entry, at time.Time) error { return entry.Pause(at) })
+		fmt.Println("Paused.")
+	},
+}
+
+// unpauseCmd represents the unpause command
+var unpauseCmd = &cobra.Command{
+	Use:   "unpause",
+	Short: "Finish the break in the running entry",
+	Long:  `Finish the current break in the entry in progress.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entry := updateBreak(func(entry *track.Entry, at time.Time) error { return entry.Unpause(at) })
+		b := entry.Breaks[len(entry.Breaks)-1]
+		fmt.Println("Unpaused after a break of", b.Duration(b.FinishedAt).Round(time.Minute))
+	},
+}
+
+// updateBreak applies update to the entry in progress at the time set by
+// --at, or now, and saves it. The entry is chosen with --timer when
+// several timers are running.
+func updateBreak(update func(entry *track.Entry, at time.Time) error) track.Entry {
+	at := time.Now().UTC().Truncate(time.Minute)
+	if atArg != "" {
+		t, err := ParseTimeArg(atArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		at = t
+	}
+
+	tracker := GetTracker(oauth.Client{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+	})
+	recent, err := track.RunningEntry(tracker.LoadEntries(), timerName())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := update(&recent, at); err != nil {
+		log.Fatal(err)
+	}
+	return tracker.SaveEntries([]track.Entry{recent})[0]
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(unpauseCmd)
+	pauseCmd.Flags().StringVar(&atArg, "at", "", "Start the break at this time (eg. --at -10m).")
+	unpauseCmd.Flags().StringVar(&atArg, "at", "", "Finish the break at this time (eg. --at -5m).")
+	pauseCmd.Flags().StringVar(&timerArg, "timer", "", "Pause the entry on this timer.")
+	unpauseCmd.Flags().StringVar(&timerArg, "timer", "", "Unpause the entry on this timer.")
+}
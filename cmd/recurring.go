@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/output"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+// recurringCmd represents the recurring command
+var recurringCmd = &cobra.Command{
+	Use:   "recurring",
+	Short: "Manage recurring entries",
+	Long: `Recurring entries are templates for entries that repeat on a schedule,
+e.g. a daily standup or a weekly planning meeting. They are set in the
+config file with an RRULE-like schedule that supports FREQ (DAILY, WEEKLY
+or MONTHLY), INTERVAL, BYDAY, BYMONTHDAY and UNTIL, e.g.
+
+  recurring:
+    - name: standup
+      description: Daily standup
+      client: acme
+      schedule: FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR
+      start: "09:30"
+      duration: 15m
+      tags: [meeting]
+    - name: planning
+      client: acme
+      schedule: FREQ=WEEKLY;INTERVAL=2;BYDAY=MO
+      from: 2021-03-01
+      start: "14:00"
+      duration: 1h
+      noBill: true
+  holidays:
+    - 2021-12-24
+    - 2021-12-31
+
+Intervals are counted from the from date. No entries are added on
+holidays.`,
+}
+
+var applyRecurringCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Add the missing occurrences of recurring entries to the log.",
+	Long: `Add the occurrences of the recurring entries between --since and --until
+that are missing from the log, e.g.
+
+  ttrack recurring apply --since monday
+
+An occurrence is missing if there is no entry for the same client with
+the same description on that day. Only occurrences that have finished by
+--until, which defaults to now, are added. --since defaults to the start
+of the week.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(cfg.Recurring) == 0 {
+			log.Fatal("No recurring entries are set in the config file.")
+		}
+		since := startOfWeek(time.Now())
+		until := time.Now()
+		if sinceArg != "" {
+			t, err := ParseTimeArg(sinceArg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			since = t
+		}
+		if untilArg != "" {
+			t, err := ParseTimeArg(untilArg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			until = t
+		}
+
+		tracker := GetTracker(oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		})
+		entries, err := track.RecurringEntries(cfg.Recurring, tracker.LoadEntries(), track.RecurringOptions{
+			Since:     since,
+			Until:     until,
+			Location:  location,
+			WeekStart: weekStart(),
+			Clients:   cfg.Clients,
+			Holidays:  cfg.Holidays,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No recurring entries are missing.")
+			return
+		}
+		writeRecurring(entries)
+		if dryRunArg {
+			return
+		}
+
+		if !yesArg && !confirm(fmt.Sprintf("Add %d entries?", len(entries))) {
+			fmt.Println("No entries were added.")
+			return
+		}
+		tracker.SaveEntries(entries)
+		fmt.Println("Added", len(entries), "entries.")
+	},
+}
+
+// writeRecurring prints the occurrences that are about to be added.
+func writeRecurring(entries []track.Entry) {
+	header := []string{"Day", "Start", "Finish", "Duration", "Client", "Description"}
+	rows := [][]string{}
+	for _, entry := range entries {
+		client := ""
+		if c, ok := track.ClientFor(cfg.Clients, entry); ok {
+			client = c.Nickname
+		}
+		startedAt := entry.StartedAt.In(location)
+		rows = append(rows, []string{
+			startedAt.Format("Mon 2006-01-02"),
+			startedAt.Format("15:04"),
+			entry.FinishedAt.In(location).Format("15:04"),
+			output.FormatDuration(track.Elapsed(entry)),
+			client,
+			entry.Description,
+		})
+	}
+	if err := output.WriteRows(os.Stdout, output.Table, header, rows); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(recurringCmd)
+	recurringCmd.AddCommand(applyRecurringCmd)
+
+	applyRecurringCmd.Flags().StringVar(&sinceArg, "since", "", "Add occurrences starting from some date (default is the start of the week).")
+	applyRecurringCmd.Flags().StringVar(&untilArg, "until", "", "Add occurrences finishing until some date (default is now).")
+	applyRecurringCmd.Flags().BoolVar(&dryRunArg, "dry-run", false, "Preview the entries without adding them.")
+	applyRecurringCmd.Flags().BoolVarP(&yesArg, "yes", "y", false, "Add the entries without asking for confirmation.")
+}
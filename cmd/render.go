@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/render"
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renderOutputArg string
+	renderFormatArg string
+	templatesArg    string
+)
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:       "render <timesheet|invoice>",
+	Short:     "Render a timesheet or an invoice as HTML or PDF.",
+	ValidArgs: render.Kinds,
+	Args:      cobra.ExactValidArgs(1),
+	Long: `Render a standalone HTML or PDF timesheet or invoice from the entries
+matching the filters, e.g.
+
+  ttrack render invoice --client acme --since 2021-03-01 --until 2021-03-31 -o acme.pdf
+
+The format is taken from the extension of --output or set with --format.
+Invoices use the rates and rounding settings, and timesheets show
+rounded durations when rounding is configured. Client details are read
+from the name, address and email of the client in the config file, and
+your own details from the business setting, e.g.
+
+  business:
+    name: Jane Doe Consulting
+    address: |
+      1 Main St
+      Springfield
+    email: jane@example.com
+
+HTML templates are read from <templates>/<client nickname>/<kind>.html
+or <templates>/<kind>.html, where the templates setting or --templates
+is a directory, and the built-in templates are used otherwise. PDFs show
+logo.png or logo.jpg from the same directories.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		kind := args[0]
+		format := renderFormatArg
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(renderOutputArg), ".")
+		}
+		if format == "htm" || format == "" {
+			format = "html"
+		}
+		if format != "html" && format != "pdf" {
+			log.Fatal("Format must be html or pdf, got ", format)
+		}
+
+		dir := cfg.Templates
+		if templatesArg != "" {
+			dir = templatesArg
+		}
+		dir, err := homedir.Expand(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var client track.Client
+		if clientFilterArg != "" {
+			client = lookupClient(clientFilterArg)
+		} else if kind == render.Invoice {
+			log.Fatal("Use --client to choose the client to invoice.")
+		}
+		params := filterParameters()
+
+		tracker := GetTracker(oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		})
+		entries := track.FilterEntries(applyQuery(tracker.LoadEntries()), params)
+
+		opts := render.Options{
+			From:     cfg.Business,
+			Client:   client,
+			Since:    params.Since,
+			Until:    params.Until,
+			Location: location,
+			Clients:  cfg.Clients,
+			Rounding: roundingPolicy(),
+		}
+		var doc render.Document
+		if kind == render.Invoice {
+			invoice, err := track.NewInvoice(entries, track.InvoiceOptions{
+				ClientID: client.ClientID,
+				By:       lineByArg,
+				Rates:    rates(),
+				Clients:  cfg.Clients,
+				Rounding: opts.Rounding,
+				Location: opts.Location,
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+			doc = render.NewInvoice(invoice, opts)
+		} else {
+			doc = render.NewTimesheet(entries, opts)
+		}
+
+		var b bytes.Buffer
+		if format == "pdf" {
+			logo := render.FindFile(dir, client.Nickname, "logo.png")
+			if logo == "" {
+				logo = render.FindFile(dir, client.Nickname, "logo.jpg")
+			}
+			err = render.WritePDF(&b, doc, logo)
+		} else {
+			err = render.WriteHTML(&b, doc, dir)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if renderOutputArg == "" || renderOutputArg == "-" {
+			if _, err := os.Stdout.Write(b.Bytes()); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+		if err := ioutil.WriteFile(renderOutputArg, b.Bytes(), 0644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Wrote", renderOutputArg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+	renderCmd.Flags().StringVarP(&renderOutputArg, "output", "o", "-", "File to write, or - for stdout.")
+	renderCmd.Flags().StringVar(&renderFormatArg, "format", "", "Document format: html or pdf (default: from the --output extension).")
+	renderCmd.Flags().StringVar(&templatesArg, "templates", "", "Directory with templates (default: the templates setting).")
+	renderCmd.Flags().StringVar(&clientFilterArg, "client", "", "Render entries for the client with this nickname.")
+	registerClientCompletion(renderCmd, "client")
+	renderCmd.Flags().StringVar(&sinceArg, "since", "", "Render entries starting from some date.")
+	renderCmd.Flags().StringVar(&periodArg, "period", "", "Render entries in a calendar period: "+strings.Join(timeexpr.Periods, ", ")+".")
+	renderCmd.Flags().StringVar(&untilArg, "until", "", "Render entries until some date.")
+	renderCmd.Flags().StringVarP(&queryArg, "query", "q", "", "Render entries matching a query (eg. -q 'tag:dev').")
+	renderCmd.Flags().StringVar(&lineByArg, "by", track.LineByDay, "Group invoice line items by one of: "+strings.Join(track.LineItemGroupings, ", ")+".")
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/report"
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+var (
+	byArgs          []string
+	reportOutputArg string
+	matrixArg       bool
+	earningsArg     bool
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize time by day, week, month, client, project or tag.",
+	Long: `Summarize the time recorded for entries with subtotals, percentages of
+the total time, and a grand total. Groupings can be nested, e.g.
+
+  ttrack report --by week,client --since 2021-03-01
+
+Entries with more than one tag are counted in the group of each tag.
+Use --matrix for a weekly timesheet with a row for each client and a
+column for each day.
+
+Use --earnings to add the amount earned for billable entries. Hourly
+rates are read from the rates setting in the config file, e.g.
+
+  currency: USD
+  rates:
+    - client: acme
+      hourly: 120
+    - client: acme
+      project: 42
+      hourly: 150
+    - tag: review
+      hourly: 90
+      currency: EUR
+
+Tag rates override project rates, and project rates override client
+rates. A rate without a client, project or tag applies to all entries.
+
+Earnings use durations rounded with the rounding setting. When rounding
+is configured, reports show the recorded and the rounded durations.
+
+Reports start at the beginning of the current week
+by default and accept the same filters as ttrack log.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		}
+		tracker := GetTracker(client)
+
+		entries := applyQuery(tracker.LoadEntries())
+		params := filterParameters()
+		if params.Since.IsZero() && queryArg == "" {
+			params.Since = startOfWeek(time.Now()).UTC()
+		}
+		if billableArg && noBillArg {
+			log.Fatal("Only one of --billable and --no-bill can be specified.")
+		}
+		if billableArg || noBillArg {
+			billable := billableArg
+			params.Billable = &billable
+		}
+		entries = track.FilterEntries(entries, params)
+
+		opts := report.Options{
+			By:        byArgs,
+			Location:  location,
+			WeekStart: weekStart(),
+			Clients:   cfg.Clients,
+			Earnings:  earningsArg,
+			Rates:     rates(),
+			Rounding:  roundingPolicy(),
+		}
+		if matrixArg {
+			if err := report.WriteMatrices(os.Stdout, report.Matrices(entries, opts), reportOutputArg); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+		res, err := report.Build(entries, opts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := report.Write(os.Stdout, res, reportOutputArg); err != nil {
+			log.Fatal(err)
+		}
+		if len(res.Unrated) > 0 {
+			fmt.Fprintf(os.Stderr, "\n%d billable entries do not have a rate and are not included in the earnings.\n", len(res.Unrated))
+		}
+	},
+}
+
+// rates returns the configured rates. Rates without a currency use the
+// currency setting.
+func rates() []track.Rate {
+	res := []track.Rate{}
+	for _, rate := range cfg.Rates {
+		if rate.Currency == "" {
+			rate.Currency = cfg.Currency
+		}
+		res = append(res, rate)
+	}
+	return res
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringSliceVar(&byArgs, "by", []string{report.Day}, "Group entries by one or more of: "+strings.Join(report.Groupings, ", ")+".")
+	reportCmd.Flags().StringVarP(&reportOutputArg, "output", "o", "table", "Output format: "+strings.Join(report.Formats, ", ")+".")
+	reportCmd.Flags().BoolVar(&earningsArg, "earnings", false, "Show the amount earned for billable entries using the configured rates.")
+	reportCmd.Flags().BoolVar(&matrixArg, "matrix", false, "Show a weekly timesheet of hours by client and day.")
+	reportCmd.Flags().StringVar(&sinceArg, "since", "", "Report entries starting from some date (default: start of this week).")
+	reportCmd.Flags().StringVar(&periodArg, "period", "", "Report entries in a calendar period: "+strings.Join(timeexpr.Periods, ", ")+".")
+	reportCmd.Flags().StringVar(&untilArg, "until", "", "Report entries until some date.")
+	reportCmd.Flags().StringVarP(&queryArg, "query", "q", "", "Report entries matching a query (eg. -q 'tag:meeting and duration>30m').")
+	reportCmd.Flags().StringSliceVarP(&tagArgs, "tag", "t", []string{}, "Report entries with all of these tags.")
+	reportCmd.Flags().StringVar(&clientFilterArg, "client", "", "Report entries for the client with this nickname.")
+	registerClientCompletion(reportCmd, "client")
+	reportCmd.Flags().StringVar(&projectFilterArg, "project", "", "Report entries for the project with this ID.")
+	reportCmd.Flags().StringVar(&matchArg, "match", "", "Report entries with descriptions containing this text.")
+	reportCmd.Flags().BoolVar(&billableArg, "billable", false, "Report billable entries.")
+	reportCmd.Flags().BoolVar(&noBillArg, "no-bill", false, "Report entries that are not billable.")
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+// resumeCmd represents the resume command
+var resumeCmd = &cobra.Command{
+	Use:   "resume [id|external-id]",
+	Short: "Start a new entry like a previous one",
+	Long: `Start a new entry with the description, client, project, tags and billable
+status of a previous entry. The entry is chosen by its ID, or with --ago n
+as the nth most recent finished entry. The most recent finished entry is
+resumed by default, e.g.
+
+  ttrack resume
+  ttrack resume 42
+  ttrack resume --ago 2 -s 13:00`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 && agoArg != "" {
+			log.Fatal("Use either an ID or --ago.")
+		}
+
+		startedAt := time.Now().UTC().Truncate(time.Minute)
+		if startedArg != "" {
+			t, err := ParseTimeArg(startedArg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			startedAt = t
+		}
+
+		tracker := GetTracker(oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		})
+		entries := tracker.LoadEntries()
+
+		var previous track.Entry
+		if len(args) == 1 {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				log.Fatal("ID must be an integer.")
+			}
+			var ok bool
+			previous, ok = track.FindEntry(entries, id)
+			if !ok {
+				log.Fatal("No entry found with ID or external ID: ", id)
+			}
+		} else {
+			finished := []track.Entry{}
+			for _, entry := range entries {
+				if !entry.InProgress() {
+					finished = append(finished, entry)
+				}
+			}
+			track.SortEntries(finished)
+			ago := 1
+			if agoArg != "" {
+				i, err := strconv.Atoi(agoArg)
+				if err != nil {
+					log.Fatal(err)
+				}
+				ago = i
+			}
+			if ago < 1 || len(finished) < ago {
+				log.Fatal("There are only ", len(finished), " finished entries, ago must be between 1 and ", len(finished), ".")
+			}
+			previous = finished[len(finished)-ago]
+		}
+
+		entry := tracker.Start(track.Resume(previous, startedAt))
+
+		fmt.Println("Resumed entry", previous.ID)
+		fmt.Println()
+		fmt.Println(entry.StringIn(location))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+	resumeCmd.ValidArgsFunction = completeOneEntryID
+	resumeCmd.Flags().StringVarP(&agoArg, "ago", "a", "", "Resume the nth most recent finished entry (eg. --ago 2 for the one before last).")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/spf13/cobra"
+)
+
+var yesArg bool
+
+// rmCmd represents the rm command
+var rmCmd = &cobra.Command{
+	Use:   "rm [id|external-id]...",
+	Short: "Delete entries.",
+	Long: `Delete entries locally and on FreshBooks. Entries are selected by ID or
+external ID, or with a query, e.g. ttrack rm -q 'desc:oops and started:2021-03-01'.
+If both are given, only the entries with those IDs that match the query are
+deleted.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && queryArg == "" {
+			return errors.New("at least one ID or a query must be set")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		client := oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		}
+		tracker := GetTracker(client)
+		entries := tracker.LoadEntries()
+
+		for _, arg := range args {
+			id, err := strconv.Atoi(arg)
+			if err != nil {
+				log.Fatal("ID must be an integer: ", arg)
+			}
+			idArgs = append(idArgs, id)
+		}
+		toDelete := selectEntries(entries)
+		if len(toDelete) == 0 {
+			fmt.Println("No entries matched the query.")
+			return
+		}
+
+		for _, entry := range toDelete {
+			fmt.Println(entry.StringIn(location))
+			fmt.Println()
+		}
+		if !yesArg && !confirm(fmt.Sprintf("Delete %d entries?", len(toDelete))) {
+			fmt.Println("No entries were deleted.")
+			return
+		}
+
+		tracker.Delete(toDelete)
+		fmt.Println("Deleted", len(toDelete), "entries.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rmCmd)
+	rmCmd.ValidArgsFunction = completeEntryIDs
+	rmCmd.Flags().BoolVarP(&yesArg, "yes", "y", false, "Delete without asking for confirmation.")
+	rmCmd.Flags().StringVarP(&queryArg, "query", "q", "", "Delete entries matching a query.")
+}
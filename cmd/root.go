@@ -4,22 +4,64 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 
 	"github.com/spf13/viper"
 
+	"github.com/hdoupe/ttrack/render"
 	"github.com/hdoupe/ttrack/track"
 )
 
 // Config describes the structure of the ttrack configuration.
 type Config struct {
-	ClientID      string         `mapstructure:"clientID"`
-	ClientSecret  string         `mapstructure:"clientSecret"`
-	LogLocation   string         `mapstructure:"logLocation"`
-	CurrentClient track.Client   `mapstructure:"currentClient"`
-	Clients       []track.Client `mapstructure:"clients"`
+	ClientID          string            `mapstructure:"clientID"`
+	ClientSecret      string            `mapstructure:"clientSecret"`
+	LogLocation       string            `mapstructure:"logLocation"`
+	CurrentClient     track.Client      `mapstructure:"currentClient"`
+	Clients           []track.Client    `mapstructure:"clients"`
+	WorkHours         map[string]string `mapstructure:"workHours"`
+	Services          map[string]int    `mapstructure:"services"`
+	Rates             []track.Rate      `mapstructure:"rates"`
+	Currency          string            `mapstructure:"currency"`
+	Rounding          RoundingConfig    `mapstructure:"rounding"`
+	Templates         string            `mapstructure:"templates"`
+	Business          render.Party      `mapstructure:"business"`
+	WeekStart         string            `mapstructure:"weekStart"`
+	Timezone          string            `mapstructure:"timezone"`
+	ContinueThreshold time.Duration     `mapstructure:"continueThreshold"`
+	ConcurrentTimers  bool              `mapstructure:"concurrentTimers"`
+	Recurring         []track.Recurring `mapstructure:"recurring"`
+	Holidays          []string          `mapstructure:"holidays"`
+	Aliases           map[string]Alias  `mapstructure:"aliases"`
+}
+
+// RoundingConfig is the default rounding and the rounding for clients by
+// nickname, e.g.
+//
+//	rounding:
+//	  mode: up
+//	  increment: 15m
+//	  minimum: 30m
+//	  apply: report
+//	  clients:
+//	    acme:
+//	      mode: nearest
+//	      increment: 6m
+type RoundingConfig struct {
+	track.Rounding `mapstructure:",squash"`
+	Clients        map[string]track.Rounding `mapstructure:"clients"`
+}
+
+// roundingPolicy returns the configured rounding policy.
+func roundingPolicy() track.RoundingPolicy {
+	policy := track.RoundingPolicy{Default: cfg.Rounding.Rounding, Clients: cfg.Rounding.Clients}
+	if err := policy.Validate(); err != nil {
+		log.Fatal("Invalid rounding config: ", err)
+	}
+	return policy
 }
 
 var (
@@ -29,6 +71,16 @@ var (
 	finishedArg string
 	logLocation string
 	durationArg string
+	forceArg    bool
+	tagArgs     []string
+	noBillArg   bool
+	tzArg       string
+	// location is the timezone used to parse and show times. It is set
+	// from --tz or the timezone setting before a command runs.
+	location = time.Local
+	// commandName is the full path of the command being run, e.g.
+	// "ttrack edit". It is recorded in the history of saved entries.
+	commandName string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -36,6 +88,10 @@ var rootCmd = &cobra.Command{
 	Use:   "ttrack",
 	Short: "Time tracking CLI application",
 	Long:  `A tool for tracking time.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		commandName = cmd.CommandPath()
+		location = loadLocation()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -59,7 +115,9 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&startedArg, "started-at", "s", "", "start time for entry")
 	rootCmd.PersistentFlags().StringVarP(&finishedArg, "finished-at", "f", "", "finish time for entry")
 	rootCmd.PersistentFlags().StringVarP(&durationArg, "duration", "d", "", "entry duration e.g. 30m (can be used instead of finished-at)")
+	rootCmd.PersistentFlags().BoolVar(&forceArg, "force", false, "save entries even if they overlap or have invalid times")
 	rootCmd.PersistentFlags().StringVar(&logLocation, "log-path", "~/.ttrack.log.json", "path to time entry log")
+	rootCmd.PersistentFlags().StringVar(&tzArg, "tz", "", "IANA timezone for parsing and showing times, e.g. America/New_York (default is the timezone setting or the system timezone)")
 }
 
 func loadConfig() {
@@ -100,7 +158,8 @@ func initConfig() {
 				log.Fatal("unable to decode into struct", err)
 			}
 		}
-		fmt.Printf("Using client: %s\n\n", cfg.CurrentClient.Nickname)
+		// Status messages go to stderr so that stdout can be parsed.
+		fmt.Fprintf(os.Stderr, "Using client: %s\n\n", cfg.CurrentClient.Nickname)
 	}
 }
 
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/hdoupe/ttrack/query"
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+)
+
+var (
+	idArgs           []int
+	clientFilterArg  string
+	projectFilterArg string
+	matchArg         string
+	regexArg         string
+	caseSensitiveArg bool
+	minArg           string
+	maxArg           string
+	queryArg         string
+	periodArg        string
+	timerArg         string
+)
+
+// hasSelection returns true if any of the entry selection flags are set.
+func hasSelection() bool {
+	return len(idArgs) > 0 || sinceArg != "" || untilArg != "" || clientFilterArg != "" ||
+		projectFilterArg != "" || matchArg != "" || regexArg != "" || minArg != "" || maxArg != "" || queryArg != "" || periodArg != ""
+}
+
+// lookupClient finds the configured client with the nickname.
+func lookupClient(nickname string) track.Client {
+	clients := track.FilterClients(cfg.Clients, track.Client{Nickname: nickname})
+	if len(clients) == 0 {
+		log.Fatal("No clients found with nickname: ", nickname)
+	}
+	if len(clients) > 1 {
+		log.Fatal("More than one client found with nickname: ", nickname)
+	}
+	return clients[0]
+}
+
+// timerName returns the timer named by --timer. Timers can only be named
+// if the concurrentTimers setting is enabled.
+func timerName() string {
+	if timerArg != "" && !cfg.ConcurrentTimers {
+		log.Fatal("--timer can only be used when the concurrentTimers setting is enabled.")
+	}
+	return timerArg
+}
+
+// clientTimer returns the timer of the client's entries, which is the
+// client's nickname if the concurrentTimers setting is enabled.
+func clientTimer(client track.Client) string {
+	if !cfg.ConcurrentTimers {
+		return ""
+	}
+	return client.Nickname
+}
+
+// newTimer returns the timer for a new entry of the client: the timer
+// named by --timer or the client's timer.
+func newTimer(client track.Client) string {
+	if timer := timerName(); timer != "" {
+		return timer
+	}
+	return clientTimer(client)
+}
+
+// selectedTimer returns the timer of the entry to act on: the timer named
+// by --timer or the timer of the client set with --client. It is empty if
+// neither is set.
+func selectedTimer() string {
+	if timer := timerName(); timer != "" {
+		return timer
+	}
+	if clientFilterArg != "" {
+		return clientTimer(lookupClient(clientFilterArg))
+	}
+	return ""
+}
+
+// applyQuery selects the entries matching the --query flag.
+func applyQuery(entries []track.Entry) []track.Entry {
+	if queryArg == "" {
+		return entries
+	}
+	q, err := query.Parse(queryArg, query.Env{Clients: cfg.Clients, Location: location, ParseTime: ParseTimeArg, ParseDuration: timeexpr.ParseDuration})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return q.Select(entries)
+}
+
+// filterParameters converts the filter flags into FilterParameters.
+func filterParameters() track.FilterParameters {
+	params := track.FilterParameters{
+		Description:   matchArg,
+		CaseSensitive: caseSensitiveArg,
+		Tags:          tagArgs,
+	}
+	if periodArg != "" {
+		if sinceArg != "" || untilArg != "" {
+			log.Fatal("Use either --period or --since and --until.")
+		}
+		since, until, err := timeexpr.PeriodRange(periodArg, time.Now().In(location), weekStart())
+		if err != nil {
+			log.Fatal(err)
+		}
+		params.Since, params.Until = since.UTC(), until.UTC()
+	}
+	if sinceArg != "" {
+		since, err := ParseTimeArg(sinceArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		params.Since = since
+	}
+	if untilArg != "" {
+		until, err := ParseTimeArg(untilArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		params.Until = until
+	}
+	if regexArg != "" {
+		pattern, err := regexp.Compile(regexArg)
+		if err != nil {
+			log.Fatal("Invalid regular expression: ", err)
+		}
+		params.Pattern = pattern
+	}
+	if clientFilterArg != "" {
+		client := lookupClient(clientFilterArg)
+		if client.ClientID == 0 {
+			// A zero ID would not filter entries at all.
+			log.Fatal("Unable to filter by client ", client.Nickname, " because it has no client ID.")
+		}
+		params.ClientID = client.ClientID
+		params.ProjectID = client.ProjectID
+	}
+	if projectFilterArg != "" {
+		projectID, err := strconv.Atoi(projectFilterArg)
+		if err != nil {
+			log.Fatal("Project ID must be an integer.")
+		}
+		params.ProjectID = projectID
+	}
+	if minArg != "" {
+		d, err := timeexpr.ParseDuration(minArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		params.MinDuration = d
+	}
+	if maxArg != "" {
+		d, err := timeexpr.ParseDuration(maxArg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		params.MaxDuration = d
+	}
+	return params
+}
+
+// selectEntries returns the entries that match the ID, query, time
+// range, client, and description flags. All of the flags must match.
+// Entries named more than once are only selected once.
+func selectEntries(entries []track.Entry) []track.Entry {
+	if len(idArgs) > 0 {
+		selected := []track.Entry{}
+		seen := map[int]bool{}
+		for _, id := range idArgs {
+			entry, ok := track.FindEntry(entries, id)
+			if !ok {
+				log.Fatal("No entry found with ID or external ID: ", id)
+			}
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				selected = append(selected, entry)
+			}
+		}
+		entries = selected
+	}
+	entries = applyQuery(entries)
+	params := filterParameters()
+	// Tags are changed rather than filtered by edit.
+	params.Tags = nil
+	return track.FilterEntries(entries, params)
+}
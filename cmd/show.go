@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/output"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+// showCmd represents the show command
+var showCmd = &cobra.Command{
+	Use:   "show <id|external-id>",
+	Short: "Show an entry and its history.",
+	Long:  `Show an entry, its client, sync status, and the history of changes made to it.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			log.Fatal("ID must be an integer.")
+		}
+
+		client := oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		}
+		tracker := GetTracker(client)
+
+		entry, ok := track.FindEntry(tracker.LoadEntries(), id)
+		if !ok {
+			log.Fatal("No entry found with ID or external ID: ", id)
+		}
+
+		fmt.Println(entry.StringIn(location))
+		fmt.Println("Project ID:", entry.ProjectID)
+		if entryClient, ok := track.ClientFor(cfg.Clients, entry); ok {
+			fmt.Println("Client:", entryClient.Nickname)
+		} else {
+			fmt.Println("Client: Unknown")
+		}
+		if len(entry.Breaks) > 0 {
+			fmt.Println("Breaks:")
+			for _, b := range entry.Breaks {
+				fmt.Printf("  %s (%v)\n", b.StringIn(location), output.FormatDuration(b.Duration(time.Now())))
+			}
+		}
+		if entry.ExternalID > 0 {
+			fmt.Println("Sync status: Synced with FreshBooks")
+		} else {
+			fmt.Println("Sync status: Local only")
+		}
+
+		fmt.Println()
+		fmt.Println("History:")
+		if len(entry.History) == 0 {
+			fmt.Println("  No changes have been recorded.")
+		}
+		for _, change := range entry.History {
+			fmt.Println(" ", change.StringIn(location))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.ValidArgsFunction = completeOneEntryID
+}
@@ -15,7 +15,20 @@ import (
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start an entry",
-	Long:  `Log the start time for an entry`,
+	Long: `Log the start time for an entry.
+
+Descriptions that start with "@" use an alias from the aliases setting
+for the description, client and tags, e.g.
+
+  ttrack start @review
+  ttrack start "@review PR 42"
+
+When the concurrentTimers setting is enabled, several entries can be in
+progress at the same time. Each client has its own timer, or the timer
+can be named with --timer, e.g.
+
+  ttrack start "On call" --timer oncall
+  ttrack start "Feature work" --client acme`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 1 {
 			return errors.New("either no arguments or one argument must be set")
@@ -38,27 +51,43 @@ var startCmd = &cobra.Command{
 			}
 			startedAt = t
 		}
+		var alias Alias
 		if len(args) == 1 {
-			description = args[0]
+			var err error
+			if description, alias, err = expandAlias(args[0]); err != nil {
+				log.Fatal(err)
+			}
 		}
 
+		client := entryClient(alias)
+		timer := newTimer(client)
+
 		entry := track.Entry{
 			StartedAt:   startedAt,
 			FinishedAt:  finishedAt,
 			Description: description,
-			ClientID:    cfg.CurrentClient.ClientID,
-			ProjectID:   cfg.CurrentClient.ProjectID,
+			ClientID:    client.ClientID,
+			ProjectID:   client.ProjectID,
+			Billable:    !noBillArg && !alias.NoBill,
+			Timer:       timer,
 		}
+		entry.AddTags(tagArgs...)
+		entry.AddTags(alias.Tags...)
 
-		client := oauth.Client{}
-		tracker := GetTracker(client)
+		tracker := GetTracker(oauth.Client{})
 		entry = tracker.Start(entry)
 
 		fmt.Println()
-		fmt.Println(entry.String())
+		fmt.Println(entry.StringIn(location))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().StringSliceVarP(&tagArgs, "tag", "t", []string{}, "Tag the entry (eg. -t meeting -t review).")
+	startCmd.Flags().BoolVar(&noBillArg, "no-bill", false, "Mark the entry as not billable.")
+	startCmd.Flags().StringVar(&clientFilterArg, "client", "", "Start the entry for the client with this nickname.")
+	startCmd.Flags().StringVar(&timerArg, "timer", "", "Start the entry on this timer (requires the concurrentTimers setting).")
+	startCmd.ValidArgsFunction = completeDescriptions
+	registerClientCompletion(startCmd, "client")
 }
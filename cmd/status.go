@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/output"
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusJSONArg  bool
+	statusShortArg bool
+)
+
+type jsonTimer struct {
+	Timer        string       `json:"timer,omitempty"`
+	Paused       bool         `json:"paused"`
+	Entry        *track.Entry `json:"entry,omitempty"`
+	Client       string       `json:"client,omitempty"`
+	Elapsed      string       `json:"elapsed"`
+	ElapsedHours float64      `json:"elapsed_hours"`
+}
+
+type jsonStatus struct {
+	Running bool `json:"running"`
+	jsonTimer
+	Timers     []jsonTimer `json:"timers,omitempty"`
+	Today      string      `json:"today"`
+	TodayHours float64     `json:"today_hours"`
+	Week       string      `json:"week"`
+	WeekHours  float64     `json:"week_hours"`
+}
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the running entry and today's and this week's totals.",
+	Long: `Show the entry in progress, how long it has been running, its client,
+and the time recorded today and this week.
+
+--short prints a single line like "1h05m review (acme)", or nothing if
+no entry is in progress, and only reads the local log, e.g. for shell
+prompts, tmux or status bars:
+
+  ttrack status --short 2>/dev/null
+
+--json prints the status as JSON and can be combined with --short.
+
+When several timers are running, all of them are shown unless one is
+chosen with --timer.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var entries []track.Entry
+		if statusShortArg {
+			local := track.Local{LogLocation: logLocation}
+			entries = local.LoadEntries()
+		} else {
+			tracker := GetTracker(oauth.Client{
+				ClientID:     cfg.ClientID,
+				ClientSecret: cfg.ClientSecret,
+			})
+			entries = tracker.LoadEntries()
+		}
+
+		now := time.Now().In(location)
+		status := track.NewStatus(entries, cfg.Clients, now, timeexpr.StartOfDay(now), startOfWeek(now))
+		if timer := timerName(); timer != "" {
+			status = status.Select(timer)
+		}
+
+		switch {
+		case statusJSONArg:
+			writeStatusJSON(status)
+		case statusShortArg:
+			if status.Running {
+				fmt.Println(shortStatus(status))
+			}
+		default:
+			writeStatus(status)
+		}
+	},
+}
+
+// timers returns the timers to show: the selected timer, or all of the
+// running timers.
+func timers(status track.Status) []track.Timer {
+	if !status.Running {
+		return nil
+	}
+	if timerArg != "" {
+		return []track.Timer{status.Timer}
+	}
+	return status.Timers
+}
+
+// shortStatus formats the running entries on one line, e.g.
+// "1h05m review (acme)" or "1h05m review (acme) | 3h00m on call (oncall)".
+func shortStatus(status track.Status) string {
+	parts := []string{}
+	for _, timer := range timers(status) {
+		res := output.FormatDuration(timer.Elapsed)
+		if timer.Entry.Description != "" {
+			res += " " + timer.Entry.Description
+		}
+		if timer.Entry.Timer != "" && !strings.EqualFold(timer.Entry.Timer, timer.Client) {
+			res += " [" + timer.Entry.Timer + "]"
+		}
+		if timer.Client != "" {
+			res += " (" + timer.Client + ")"
+		}
+		if timer.Paused {
+			res += " [paused]"
+		}
+		parts = append(parts, res)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func writeStatus(status track.Status) {
+	if !status.Running {
+		fmt.Println("No entry is in progress.")
+	}
+	for _, timer := range timers(status) {
+		client := timer.Client
+		if client == "" {
+			client = "Unknown"
+		}
+		if timer.Entry.Timer != "" {
+			fmt.Println("Timer:", timer.Entry.Timer)
+		}
+		fmt.Println("Running:", timer.Entry.Description)
+		fmt.Println("Client:", client)
+		fmt.Println("Started At:", timer.Entry.StartedAt.In(location).Format(time.UnixDate))
+		fmt.Println("Elapsed:", output.FormatDuration(timer.Elapsed))
+		if timer.Paused {
+			fmt.Println("Paused since:", timer.Entry.Breaks[len(timer.Entry.Breaks)-1].StartedAt.In(location).Format(time.Kitchen))
+		}
+		fmt.Println()
+	}
+	fmt.Println("Today:", output.FormatDuration(status.Today))
+	fmt.Println("This week:", output.FormatDuration(status.Week))
+}
+
+func newJSONTimer(timer track.Timer) jsonTimer {
+	entry := timer.Entry
+	return jsonTimer{
+		Timer:        entry.Timer,
+		Paused:       timer.Paused,
+		Entry:        &entry,
+		Client:       timer.Client,
+		Elapsed:      output.FormatDuration(timer.Elapsed),
+		ElapsedHours: timer.Elapsed.Hours(),
+	}
+}
+
+func writeStatusJSON(status track.Status) {
+	res := jsonStatus{
+		Running:    status.Running,
+		jsonTimer:  jsonTimer{Elapsed: output.FormatDuration(0)},
+		Today:      output.FormatDuration(status.Today),
+		TodayHours: status.Today.Hours(),
+		Week:       output.FormatDuration(status.Week),
+		WeekHours:  status.Week.Hours(),
+	}
+	if status.Running {
+		res.jsonTimer = newJSONTimer(status.Timer)
+	}
+	if shown := timers(status); len(shown) > 1 {
+		for _, timer := range shown {
+			res.Timers = append(res.Timers, newJSONTimer(timer))
+		}
+	}
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&statusJSONArg, "json", false, "Print the status as JSON.")
+	statusCmd.Flags().BoolVar(&statusShortArg, "short", false, "Print the running entry on one line, reading only the local log.")
+	statusCmd.Flags().StringVar(&timerArg, "timer", "", "Show the entry on this timer.")
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hdoupe/ttrack/oauth"
+	"github.com/hdoupe/ttrack/track"
+	"github.com/spf13/cobra"
+)
+
+// switchCmd represents the switch command
+var switchCmd = &cobra.Command{
+	Use:   "switch [description]",
+	Short: "Finish the running entry and start a new one",
+	Long: `Finish the entry in progress and start a new entry at the same time,
+e.g.
+
+  ttrack switch "Code review" --client acme -t review
+
+The new entry is for the current client unless --client is set. The
+description can be an alias like "@review". If the finished entry is
+rounded when it is finished, only its duration is rounded so that the
+new entry still starts when it ends.
+
+When several timers are running, choose the one to switch with --timer
+or --client. The new entry continues the same timer if it is for the
+same client. Otherwise it starts on the timer of its client, which must
+not be running.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return errors.New("either no arguments or one argument must be set")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Switching time entries...")
+		var startedAt time.Time
+		var description string = ""
+
+		if startedArg == "" {
+			t := time.Now().UTC()
+			startedAt = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+		} else {
+			t, err := ParseTimeArg(startedArg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			startedAt = t
+		}
+		var alias Alias
+		if len(args) == 1 {
+			var err error
+			if description, alias, err = expandAlias(args[0]); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		client := entryClient(alias)
+
+		entry := track.Entry{
+			StartedAt:   startedAt,
+			Description: description,
+			ClientID:    client.ClientID,
+			ProjectID:   client.ProjectID,
+			Billable:    !noBillArg && !alias.NoBill,
+			Timer:       clientTimer(client),
+		}
+		entry.AddTags(tagArgs...)
+		entry.AddTags(alias.Tags...)
+
+		tracker := GetTracker(oauth.Client{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+		})
+		finished, started := tracker.Switch(selectedTimer(), entry)
+
+		fmt.Println()
+		fmt.Println("Finished:")
+		fmt.Println(finished.StringIn(location))
+		fmt.Println()
+		fmt.Println("Started:")
+		fmt.Println(started.StringIn(location))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+	switchCmd.Flags().StringVar(&clientFilterArg, "client", "", "Switch the timer of the client with this nickname and start the new entry for it.")
+	switchCmd.Flags().StringSliceVarP(&tagArgs, "tag", "t", []string{}, "Tag the new entry (eg. -t meeting -t review).")
+	switchCmd.Flags().BoolVar(&noBillArg, "no-bill", false, "Mark the new entry as not billable.")
+	switchCmd.Flags().StringVar(&timerArg, "timer", "", "Switch the entry on this timer.")
+	switchCmd.ValidArgsFunction = completeDescriptions
+	registerClientCompletion(switchCmd, "client")
+}
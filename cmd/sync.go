@@ -37,6 +37,7 @@ var syncCmd = &cobra.Command{
 		fbTracker := track.FreshBooks{
 			LogLocation: logLocation,
 			Credentials: creds,
+			Command:     commandName,
 		}
 		fbTracker.SyncEntries()
 	},
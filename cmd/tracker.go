@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/hdoupe/ttrack/oauth"
 	"github.com/hdoupe/ttrack/track"
@@ -19,7 +20,7 @@ func GetTracker(client oauth.Client) track.Tracker {
 			log.Fatal(err)
 		}
 		if client.IsExpired(creds) {
-			fmt.Println("Refreshing expired credentials...")
+			fmt.Fprintln(os.Stderr, "Refreshing expired credentials...")
 			var refreshErr error
 			creds, refreshErr = client.Refresh(creds)
 			if refreshErr != nil {
@@ -30,9 +31,22 @@ func GetTracker(client oauth.Client) track.Tracker {
 		tracker = &track.FreshBooks{
 			Credentials: creds,
 			LogLocation: logLocation,
+			Command:     commandName,
+			Force:       forceArg,
+			Services:    cfg.Services,
+			Rounding:    roundingPolicy(),
+			Clients:     cfg.Clients,
+			Location:    location,
 		}
 	} else {
-		tracker = &track.Local{LogLocation: logLocation}
+		tracker = &track.Local{
+			LogLocation: logLocation,
+			Command:     commandName,
+			Force:       forceArg,
+			Rounding:    roundingPolicy(),
+			Clients:     cfg.Clients,
+			Location:    location,
+		}
 	}
 
 	return tracker
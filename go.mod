@@ -3,6 +3,7 @@ module github.com/hdoupe/ttrack
 go 1.15
 
 require (
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/spf13/cobra v1.1.3
 	github.com/spf13/viper v1.7.1
@@ -86,7 +86,7 @@ func (oauthClient *Client) Exchange(authCode string) (Credentials, error) {
 		msg := fmt.Sprintf("Unexpected error when authenticating credentials (%d)", resp.StatusCode)
 		return Credentials{}, fmt.Errorf(msg)
 	}
-	fmt.Println(resp.Status)
+	fmt.Fprintln(os.Stderr, resp.Status)
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -134,7 +134,7 @@ func (oauthClient *Client) Refresh(credentials Credentials) (Credentials, error)
 		msg := fmt.Sprintf("Unexpected error when refreshing credentials (%d)", resp.StatusCode)
 		return Credentials{}, fmt.Errorf(msg)
 	}
-	fmt.Println(resp.Status)
+	fmt.Fprintln(os.Stderr, resp.Status)
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -209,7 +209,7 @@ func (oauthClient *Client) Cache(credentials Credentials) {
 		log.Fatal(err)
 	}
 
-	fmt.Println("Writing credentials to:", location)
+	fmt.Fprintln(os.Stderr, "Writing credentials to:", location)
 
 	// nolint: gosec
 	if err := ioutil.WriteFile(location, data, 0644); err != nil {
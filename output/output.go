@@ -0,0 +1,316 @@
+// Package output writes time entries and tables in formats for people
+// and for programs.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+// Output formats.
+const (
+	Table    = "table"
+	JSON     = "json"
+	JSONL    = "jsonl"
+	CSV      = "csv"
+	TSV      = "tsv"
+	Markdown = "markdown"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{Table, JSON, JSONL, CSV, TSV, Markdown}
+
+// Writer writes entries with times in Location and client nicknames
+// resolved from Clients. Rounded durations use Rounding.
+type Writer struct {
+	Location *time.Location
+	Clients  []track.Client
+	Rounding track.RoundingPolicy
+}
+
+// Record is the representation of an entry used by all of the output
+// formats. Times are RFC 3339 in the writer's location and durations are
+// formatted with FormatDuration.
+type Record struct {
+	ID           int      `json:"id"`
+	ExternalID   int      `json:"external_id,omitempty"`
+	StartedAt    string   `json:"started_at"`
+	FinishedAt   string   `json:"finished_at"`
+	Duration     string   `json:"duration"`
+	Hours        float64  `json:"hours"`
+	Client       string   `json:"client"`
+	ClientID     int      `json:"client_id"`
+	ProjectID    int      `json:"project_id"`
+	Description  string   `json:"description"`
+	Tags         []string `json:"tags"`
+	Billable     bool     `json:"billable"`
+	Rounded      string   `json:"rounded"`
+	RoundedHours float64  `json:"rounded_hours"`
+}
+
+var recordHeader = []string{
+	"id", "external_id", "started_at", "finished_at", "duration", "hours",
+	"client", "client_id", "project_id", "description", "tags", "billable",
+	"rounded", "rounded_hours",
+}
+
+func (record *Record) row() []string {
+	return []string{
+		fmt.Sprint(record.ID),
+		fmt.Sprint(record.ExternalID),
+		record.StartedAt,
+		record.FinishedAt,
+		record.Duration,
+		fmt.Sprintf("%.2f", record.Hours),
+		record.Client,
+		fmt.Sprint(record.ClientID),
+		fmt.Sprint(record.ProjectID),
+		record.Description,
+		strings.Join(record.Tags, ","),
+		fmt.Sprint(record.Billable),
+		record.Rounded,
+		fmt.Sprintf("%.2f", record.RoundedHours),
+	}
+}
+
+// FormatDuration formats durations rounded to the minute, e.g. 1h30m.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%s%dm", sign, minutes)
+	}
+	return fmt.Sprintf("%s%dh%02dm", sign, hours, minutes)
+}
+
+func (w *Writer) location() *time.Location {
+	if w.Location == nil {
+		return time.Local
+	}
+	return w.Location
+}
+
+// ClientName returns the nickname of the entry's client or an empty
+// string if the client is not configured.
+func (w *Writer) ClientName(entry track.Entry) string {
+	if client, ok := track.ClientFor(w.Clients, entry); ok {
+		return client.Nickname
+	}
+	return ""
+}
+
+// Record converts an entry to a Record.
+func (w *Writer) Record(entry track.Entry) Record {
+	loc := w.location()
+	finishedAt := ""
+	if !entry.InProgress() {
+		finishedAt = entry.FinishedAt.In(loc).Format(time.RFC3339)
+	}
+	duration := track.Elapsed(entry)
+	rounded := w.Rounding.Rounded(w.Clients, entry)
+	tags := entry.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return Record{
+		ID:           entry.ID,
+		ExternalID:   entry.ExternalID,
+		StartedAt:    entry.StartedAt.In(loc).Format(time.RFC3339),
+		FinishedAt:   finishedAt,
+		Duration:     FormatDuration(duration),
+		Hours:        duration.Hours(),
+		Client:       w.ClientName(entry),
+		ClientID:     entry.ClientID,
+		ProjectID:    entry.ProjectID,
+		Description:  entry.Description,
+		Tags:         tags,
+		Billable:     entry.Billable,
+		Rounded:      FormatDuration(rounded),
+		RoundedHours: rounded.Hours(),
+	}
+}
+
+// Write writes entries in one of the output formats.
+func (w *Writer) Write(out io.Writer, entries []track.Entry, format string) error {
+	switch format {
+	case JSON, JSONL:
+		records := []Record{}
+		for _, entry := range entries {
+			records = append(records, w.Record(entry))
+		}
+		return WriteJSON(out, records, format)
+	case CSV, TSV:
+		rows := [][]string{}
+		for _, entry := range entries {
+			record := w.Record(entry)
+			rows = append(rows, record.row())
+		}
+		return WriteRows(out, format, recordHeader, rows)
+	case Table, Markdown:
+		loc := w.location()
+		rounding := !w.Rounding.IsZero()
+		header := []string{"ID", "Date", "Start", "Finish", "Duration"}
+		if rounding {
+			header = append(header, "Rounded")
+		}
+		header = append(header, "Client", "Tags", "Description")
+		rows := [][]string{}
+		var total, totalRounded time.Duration
+		for _, entry := range entries {
+			finish := "-"
+			if !entry.InProgress() {
+				finish = entry.FinishedAt.In(loc).Format("15:04")
+			}
+			duration := track.Elapsed(entry)
+			total += duration
+			row := []string{
+				fmt.Sprint(entry.ID),
+				entry.StartedAt.In(loc).Format("2006-01-02"),
+				entry.StartedAt.In(loc).Format("15:04"),
+				finish,
+				FormatDuration(duration),
+			}
+			if rounding {
+				rounded := w.Rounding.Rounded(w.Clients, entry)
+				totalRounded += rounded
+				row = append(row, FormatDuration(rounded))
+			}
+			rows = append(rows, append(row, w.ClientName(entry), strings.Join(entry.Tags, ","), entry.Description))
+		}
+		totals := []string{"Total", "", "", "", FormatDuration(total)}
+		if rounding {
+			totals = append(totals, FormatDuration(totalRounded))
+		}
+		rows = append(rows, append(totals, "", "", ""))
+		return WriteRows(out, format, header, rows)
+	default:
+		return fmt.Errorf("unknown output format %q, use one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// WriteJSON writes a slice of values as a JSON array or, for JSONL, as
+// one JSON value per line.
+func WriteJSON(out io.Writer, values interface{}, format string) error {
+	if format == JSONL {
+		data, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if _, err := fmt.Fprintln(out, string(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// WriteRows writes a table as an aligned table, CSV, TSV, or Markdown.
+func WriteRows(out io.Writer, format string, header []string, rows [][]string) error {
+	switch format {
+	case CSV, TSV:
+		writer := csv.NewWriter(out)
+		if format == TSV {
+			writer.Comma = '\t'
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case Markdown:
+		escape := func(row []string) string {
+			cells := make([]string, len(row))
+			for ix, cell := range row {
+				cells[ix] = strings.ReplaceAll(cell, "|", "\\|")
+			}
+			return "| " + strings.Join(cells, " | ") + " |"
+		}
+		lines := []string{escape(header)}
+		separator := make([]string, len(header))
+		for ix := range separator {
+			separator[ix] = "---"
+		}
+		lines = append(lines, "| "+strings.Join(separator, " | ")+" |")
+		for _, row := range rows {
+			lines = append(lines, escape(row))
+		}
+		_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+		return err
+	case Table:
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unknown table format %q, use one of %s", format, strings.Join([]string{Table, CSV, TSV, Markdown}, ", "))
+	}
+}
+
+// Template parses a text/template that is executed for each entry. The
+// template can use these functions:
+//
+//	duration  formats the entry's duration, e.g. {{duration .}}
+//	hours     the entry's duration in decimal hours, e.g. {{printf "%.2f" (hours .)}}
+//	rounded   formats the entry's rounded duration, e.g. {{rounded .}}
+//	time      formats a time in the writer's location, e.g. {{time "15:04" .StartedAt}}
+//	client    the nickname of the entry's client, e.g. {{client .}}
+//	join      joins strings, e.g. {{join .Tags ","}}
+func (w *Writer) Template(text string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"duration": func(entry track.Entry) string { return FormatDuration(track.Elapsed(entry)) },
+		"hours":    func(entry track.Entry) float64 { return track.Elapsed(entry).Hours() },
+		"rounded":  func(entry track.Entry) string { return FormatDuration(w.Rounding.Rounded(w.Clients, entry)) },
+		"time": func(layout string, t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.In(w.location()).Format(layout)
+		},
+		"client": w.ClientName,
+		"join":   strings.Join,
+	}
+	return template.New("format").Funcs(funcs).Parse(text)
+}
+
+// WriteTemplate executes the template for each entry. A new line is
+// written after each entry.
+func (w *Writer) WriteTemplate(out io.Writer, entries []track.Entry, tmpl *template.Template) error {
+	for _, entry := range entries {
+		if err := tmpl.Execute(out, entry); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
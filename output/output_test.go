@@ -0,0 +1,101 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+func mockEntries() []track.Entry {
+	started := time.Date(2021, 3, 30, 14, 30, 0, 0, time.UTC)
+	return []track.Entry{
+		{
+			ID:          1,
+			StartedAt:   started,
+			FinishedAt:  started.Add(90 * time.Minute),
+			Duration:    90 * 60,
+			Description: "Write code | tests",
+			ClientID:    1,
+			Tags:        []string{"dev", "review"},
+			Billable:    true,
+		},
+		{
+			ID:          2,
+			StartedAt:   started.Add(2 * time.Hour),
+			FinishedAt:  started.Add(2*time.Hour + 15*time.Minute),
+			Duration:    15 * 60,
+			Description: "Standup",
+		},
+	}
+}
+
+func writer() Writer {
+	loc := time.FixedZone("EDT", -4*60*60)
+	return Writer{Location: loc, Clients: []track.Client{{Nickname: "acme", ClientID: 1}}}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                            "0m",
+		45 * time.Minute:             "45m",
+		90*time.Minute + time.Second: "1h30m",
+		26 * time.Hour:               "26h00m",
+		-15 * time.Minute:            "-15m",
+	}
+	for d, expected := range tests {
+		if got := FormatDuration(d); got != expected {
+			t.Errorf("FormatDuration(%v) = %s, expected %s", d, got, expected)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	w := writer()
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{CSV, []string{"id,external_id,started_at", "1,0,2021-03-30T10:30:00-04:00,2021-03-30T12:00:00-04:00,1h30m,1.50,acme,1,0,Write code | tests,\"dev,review\",true"}},
+		{TSV, []string{"2\t0\t2021-03-30T12:30:00-04:00"}},
+		{JSONL, []string{`{"id":1,"started_at":"2021-03-30T10:30:00-04:00"`, `"tags":[]`}},
+		{JSON, []string{`"duration": "15m"`}},
+		{Markdown, []string{"| ID | Date |", `Write code \| tests`, "| Total |  |  |  | 1h45m |"}},
+		{Table, []string{"10:30  12:00   1h30m", "Total"}},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		if err := w.Write(&out, mockEntries(), test.format); err != nil {
+			t.Errorf("(%s) Unexpected error: %v", test.format, err)
+			continue
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("(%s) Expected output to contain %q, got:\n%s", test.format, expected, out.String())
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	if err := w.Write(&out, mockEntries(), "yaml"); err == nil {
+		t.Errorf("Expected error for unknown format")
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	w := writer()
+	tmpl, err := w.Template(`{{.ID}} {{time "15:04" .StartedAt}} {{duration .}} {{client .}} {{join .Tags ","}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := w.WriteTemplate(&out, mockEntries(), tmpl); err != nil {
+		t.Fatal(err)
+	}
+	expected := "1 10:30 1h30m acme dev,review\n2 12:30 15m  \n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
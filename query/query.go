@@ -0,0 +1,499 @@
+// Package query parses expressions for selecting time entries, e.g.
+//
+//	client:acme and tag:meeting and started>=2021-03-01 and duration>30m and desc~"deploy"
+//
+// Terms are made of a field, an operator, and a value. Terms can be
+// combined with "and", "or", "not" and parentheses. Terms next to each
+// other without "and" or "or" are combined with "and".
+//
+// Fields and their operators:
+//
+//	client       :  =  !=           client nickname
+//	project      :  =  !=           project ID
+//	tag          :  =  !=           tag
+//	desc         :  =  !=  ~  !~    ':' contains, '=' equals, '~' matches a regular expression
+//	started      :  =  >  >=  <  <=  ':' and '=' match the 24 hours from the given time
+//	finished     :  =  >  >=  <  <=
+//	duration     =  !=  >  >=  <  <=
+//	billable     :  =               true or false
+//	id           :  =               ID or external ID
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+// Env provides the information needed to resolve the values in a query.
+type Env struct {
+	Clients []track.Client
+	// Location is the timezone of dates in queries. Defaults to
+	// time.Local.
+	Location *time.Location
+	// ParseTime parses values for started and finished. Defaults to
+	// YYYY-MM-DD dates in Location.
+	ParseTime func(string) (time.Time, error)
+	// ParseDuration parses values for duration. Defaults to
+	// time.ParseDuration.
+	ParseDuration func(string) (time.Duration, error)
+}
+
+// Error describes where and why a query could not be parsed.
+type Error struct {
+	Query string
+	Pos   int
+	Msg   string
+}
+
+// Error returns the message and points to where the problem is in the
+// query.
+func (err *Error) Error() string {
+	return fmt.Sprintf("%s at position %d:\n  %s\n  %s^", err.Msg, err.Pos+1, err.Query, strings.Repeat(" ", err.Pos))
+}
+
+// Query is a parsed query expression.
+type Query struct {
+	root   node
+	params track.FilterParameters
+}
+
+// Parse compiles a query expression.
+func Parse(input string, env Env) (*Query, error) {
+	if env.Location == nil {
+		env.Location = time.Local
+	}
+	if env.ParseTime == nil {
+		env.ParseTime = func(value string) (time.Time, error) {
+			return time.ParseInLocation("2006-01-02", value, env.Location)
+		}
+	}
+	if env.ParseDuration == nil {
+		env.ParseDuration = time.ParseDuration
+	}
+	p := parser{input: input, env: env}
+	p.skipSpace()
+	if p.done() {
+		return nil, p.errorf(p.pos, "query is empty")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.done() {
+		return nil, p.errorf(p.pos, "unexpected %q", p.input[p.pos:])
+	}
+	q := &Query{root: root}
+	q.params = timeRange(root)
+	return q, nil
+}
+
+// Match returns true if the entry matches the query.
+func (q *Query) Match(entry track.Entry) bool {
+	return q.root.match(entry)
+}
+
+// Parameters returns the time range of the query that must match for
+// every entry. These can be passed to track.FilterEntries to narrow down
+// the entries before calling Match.
+func (q *Query) Parameters() track.FilterParameters {
+	return q.params
+}
+
+// Select returns the entries that match the query sorted by start time.
+func (q *Query) Select(entries []track.Entry) []track.Entry {
+	res := []track.Entry{}
+	for _, entry := range track.FilterEntries(entries, q.params) {
+		if q.Match(entry) {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+type node interface {
+	match(entry track.Entry) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n *andNode) match(entry track.Entry) bool { return n.left.match(entry) && n.right.match(entry) }
+
+type orNode struct{ left, right node }
+
+func (n *orNode) match(entry track.Entry) bool { return n.left.match(entry) || n.right.match(entry) }
+
+type notNode struct{ inner node }
+
+func (n *notNode) match(entry track.Entry) bool { return !n.inner.match(entry) }
+
+type termNode struct {
+	field string
+	op    string
+	at    time.Time
+	test  func(entry track.Entry) bool
+}
+
+func (n *termNode) match(entry track.Entry) bool { return n.test(entry) }
+
+// timeRange collects the start time limits from the terms that every
+// entry must match, i.e. terms that are only combined with "and".
+func timeRange(root node) track.FilterParameters {
+	params := track.FilterParameters{}
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case *andNode:
+			walk(n.left)
+			walk(n.right)
+		case *termNode:
+			if n.field != "started" {
+				return
+			}
+			var since, until time.Time
+			switch n.op {
+			case ">=":
+				since = n.at
+			case ">":
+				since = n.at.Add(time.Nanosecond)
+			case "<":
+				until = n.at
+			case "<=":
+				until = n.at.Add(time.Nanosecond)
+			case ":", "=":
+				since, until = n.at, n.at.Add(24*time.Hour)
+			}
+			if !since.IsZero() && since.After(params.Since) {
+				params.Since = since
+			}
+			if !until.IsZero() && (params.Until.IsZero() || until.Before(params.Until)) {
+				params.Until = until
+			}
+		}
+	}
+	walk(root)
+	return params
+}
+
+type parser struct {
+	input string
+	pos   int
+	env   Env
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &Error{Query: p.input, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+// keyword consumes the keyword if it is next in the input.
+func (p *parser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.input) && isWordChar(p.input[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if !p.keyword("and") {
+			// Terms next to each other are combined with "and".
+			p.skipSpace()
+			if p.done() || p.input[p.pos] == ')' {
+				return left, nil
+			}
+			save := p.pos
+			if p.keyword("or") {
+				p.pos = save
+				return left, nil
+			}
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.keyword("not") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{inner: inner}, nil
+	}
+	p.skipSpace()
+	if p.done() {
+		return nil, p.errorf(p.pos, "expected a term like tag:meeting")
+	}
+	if p.input[p.pos] == '(' {
+		open := p.pos
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.done() || p.input[p.pos] != ')' {
+			return nil, p.errorf(open, "unclosed parenthesis")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseTerm()
+}
+
+var operators = []string{">=", "<=", "!=", "!~", ":", "=", ">", "<", "~"}
+
+func (p *parser) parseTerm() (node, error) {
+	start := p.pos
+	for !p.done() && isWordChar(p.input[p.pos]) {
+		p.pos++
+	}
+	field := strings.ToLower(p.input[start:p.pos])
+	if field == "" {
+		return nil, p.errorf(start, "expected a field name")
+	}
+
+	opPos := p.pos
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(p.input[p.pos:], candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, p.errorf(opPos, "expected an operator such as ':' or '>=' after %q", field)
+	}
+	p.pos += len(op)
+
+	valuePos := p.pos
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, p.errorf(valuePos, "expected a value after %q", field+op)
+	}
+	return p.compile(field, op, value, start, opPos, valuePos)
+}
+
+// parseValue reads a quoted string or the characters up to the next
+// space or parenthesis.
+func (p *parser) parseValue() (string, error) {
+	if !p.done() && p.input[p.pos] == '"' {
+		open := p.pos
+		var b strings.Builder
+		p.pos++
+		for !p.done() {
+			c := p.input[p.pos]
+			if c == '\\' && p.pos+1 < len(p.input) {
+				b.WriteByte(p.input[p.pos+1])
+				p.pos += 2
+				continue
+			}
+			if c == '"' {
+				p.pos++
+				return b.String(), nil
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+		return "", p.errorf(open, "unclosed quote")
+	}
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(" \t\n()", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos], nil
+}
+
+func (p *parser) checkOp(field string, op string, opPos int, allowed ...string) error {
+	for _, a := range allowed {
+		if op == a {
+			return nil
+		}
+	}
+	return p.errorf(opPos, "%q can not be used with %s, use one of %s", op, field, strings.Join(allowed, " "))
+}
+
+func negate(op string, test func(entry track.Entry) bool) func(entry track.Entry) bool {
+	if op == "!=" || op == "!~" {
+		return func(entry track.Entry) bool { return !test(entry) }
+	}
+	return test
+}
+
+func compareTime(op string, a time.Time, b time.Time) bool {
+	switch op {
+	case ">":
+		return a.After(b)
+	case ">=":
+		return !a.Before(b)
+	case "<":
+		return a.Before(b)
+	case "<=":
+		return !a.After(b)
+	default:
+		return !a.Before(b) && a.Before(b.Add(24*time.Hour))
+	}
+}
+
+func (p *parser) compile(field string, op string, value string, fieldPos int, opPos int, valuePos int) (node, error) {
+	term := &termNode{field: field, op: op}
+	switch field {
+	case "client":
+		if err := p.checkOp(field, op, opPos, ":", "=", "!="); err != nil {
+			return nil, err
+		}
+		clients := track.FilterClients(p.env.Clients, track.Client{Nickname: value})
+		if len(clients) != 1 {
+			return nil, p.errorf(valuePos, "unknown client %q", value)
+		}
+		client := clients[0]
+		term.test = negate(op, func(entry track.Entry) bool {
+			return entry.ClientID == client.ClientID && entry.ProjectID == client.ProjectID
+		})
+	case "project":
+		if err := p.checkOp(field, op, opPos, ":", "=", "!="); err != nil {
+			return nil, err
+		}
+		projectID, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, p.errorf(valuePos, "project must be an integer ID, got %q", value)
+		}
+		term.test = negate(op, func(entry track.Entry) bool { return entry.ProjectID == projectID })
+	case "tag":
+		if err := p.checkOp(field, op, opPos, ":", "=", "!="); err != nil {
+			return nil, err
+		}
+		term.test = negate(op, func(entry track.Entry) bool { return entry.HasTag(value) })
+	case "desc", "description":
+		if err := p.checkOp(field, op, opPos, ":", "=", "!=", "~", "!~"); err != nil {
+			return nil, err
+		}
+		switch op {
+		case "~", "!~":
+			pattern, err := regexp.Compile(value)
+			if err != nil {
+				return nil, p.errorf(valuePos, "invalid regular expression: %v", err)
+			}
+			term.test = negate(op, func(entry track.Entry) bool { return pattern.MatchString(entry.Description) })
+		case ":":
+			lower := strings.ToLower(value)
+			term.test = func(entry track.Entry) bool { return strings.Contains(strings.ToLower(entry.Description), lower) }
+		default:
+			term.test = negate(op, func(entry track.Entry) bool { return strings.EqualFold(entry.Description, value) })
+		}
+	case "started", "finished":
+		if err := p.checkOp(field, op, opPos, ":", "=", ">", ">=", "<", "<="); err != nil {
+			return nil, err
+		}
+		t, err := p.env.ParseTime(value)
+		if err != nil {
+			return nil, p.errorf(valuePos, "invalid time %q: %v", value, err)
+		}
+		term.at = t
+		if field == "started" {
+			term.test = func(entry track.Entry) bool { return compareTime(op, entry.StartedAt, t) }
+		} else {
+			term.test = func(entry track.Entry) bool {
+				return !entry.InProgress() && compareTime(op, entry.FinishedAt, t)
+			}
+		}
+	case "duration":
+		if err := p.checkOp(field, op, opPos, "=", "!=", ">", ">=", "<", "<="); err != nil {
+			return nil, err
+		}
+		d, err := p.env.ParseDuration(value)
+		if err != nil {
+			return nil, p.errorf(valuePos, "invalid duration %q: %v", value, err)
+		}
+		term.test = func(entry track.Entry) bool {
+			elapsed := track.Elapsed(entry)
+			switch op {
+			case "!=":
+				return elapsed != d
+			case ">":
+				return elapsed > d
+			case ">=":
+				return elapsed >= d
+			case "<":
+				return elapsed < d
+			case "<=":
+				return elapsed <= d
+			default:
+				return elapsed == d
+			}
+		}
+	case "billable":
+		if err := p.checkOp(field, op, opPos, ":", "="); err != nil {
+			return nil, err
+		}
+		billable, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, p.errorf(valuePos, "billable must be true or false, got %q", value)
+		}
+		term.test = func(entry track.Entry) bool { return entry.Billable == billable }
+	case "id":
+		if err := p.checkOp(field, op, opPos, ":", "="); err != nil {
+			return nil, err
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, p.errorf(valuePos, "id must be an integer, got %q", value)
+		}
+		term.test = func(entry track.Entry) bool {
+			return entry.ID == id || (entry.ExternalID != 0 && entry.ExternalID == id)
+		}
+	default:
+		return nil, p.errorf(fieldPos, "unknown field %q, use one of client, project, tag, desc, started, finished, duration, billable, id", field)
+	}
+	return term, nil
+}
@@ -0,0 +1,147 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+func mockEntries() []track.Entry {
+	day := time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)
+	entry := func(id int, offset time.Duration, duration time.Duration, description string, clientID int, tags ...string) track.Entry {
+		return track.Entry{
+			ID:          id,
+			StartedAt:   day.Add(offset),
+			FinishedAt:  day.Add(offset + duration),
+			Duration:    int(duration.Seconds()),
+			Description: description,
+			ClientID:    clientID,
+			Tags:        tags,
+			Billable:    clientID != 0,
+		}
+	}
+	return []track.Entry{
+		entry(1, 0, time.Hour, "Deploy the app", 1, "ops"),
+		entry(2, time.Hour, 30*time.Minute, "Standup", 1, "meeting"),
+		entry(3, 24*time.Hour, 2*time.Hour, "Planning meeting about deploy", 2, "meeting"),
+		entry(4, 48*time.Hour, 15*time.Minute, "Lunch", 0),
+	}
+}
+
+func env() Env {
+	return Env{
+		Clients: []track.Client{{Nickname: "acme", ClientID: 1}, {Nickname: "globex", ClientID: 2}},
+		ParseTime: func(value string) (time.Time, error) {
+			return time.Parse("2006-01-02", value)
+		},
+	}
+}
+
+func ids(entries []track.Entry) []int {
+	res := []int{}
+	for _, entry := range entries {
+		res = append(res, entry.ID)
+	}
+	return res
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{`client:acme`, []int{1, 2}},
+		{`client:acme and tag:meeting`, []int{2}},
+		{`tag:meeting or tag:ops`, []int{1, 2, 3}},
+		{`not tag:meeting`, []int{1, 4}},
+		{`desc~"^Deploy"`, []int{1}},
+		{`desc:deploy`, []int{1, 3}},
+		{`desc="lunch"`, []int{4}},
+		{`desc!~deploy`, []int{1, 2, 4}},
+		{`duration>30m`, []int{1, 3}},
+		{`duration<=30m`, []int{2, 4}},
+		{`started>=2021-03-02`, []int{3, 4}},
+		{`started:2021-03-02`, []int{3}},
+		{`finished<2021-03-02`, []int{1, 2}},
+		{`billable:false`, []int{4}},
+		{`id:3`, []int{3}},
+		{`client:globex or (client:acme and duration>=1h)`, []int{1, 3}},
+		{`tag:meeting client:globex`, []int{3}},
+		{`client:acme and tag:meeting and started>=2021-03-01 and duration>10m and desc~"Stand"`, []int{2}},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.query, env())
+		if err != nil {
+			t.Errorf("(%s) Unexpected error: %v", test.query, err)
+			continue
+		}
+		got := ids(q.Select(mockEntries()))
+		if len(got) != len(test.expected) {
+			t.Errorf("(%s) Expected %v, got %v", test.query, test.expected, got)
+			continue
+		}
+		for ix := range got {
+			if got[ix] != test.expected[ix] {
+				t.Errorf("(%s) Expected %v, got %v", test.query, test.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestParameters(t *testing.T) {
+	q, err := Parse(`started>=2021-03-02 and started<2021-03-03 and tag:meeting`, env())
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := q.Parameters()
+	if !params.Since.Equal(time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)) || !params.Until.Equal(time.Date(2021, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected time range: %v - %v", params.Since, params.Until)
+	}
+
+	q, err = Parse(`started>=2021-03-02 or tag:meeting`, env())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params := q.Parameters(); !params.Since.IsZero() || !params.Until.IsZero() {
+		t.Errorf("Expected no time range for or, got %v - %v", params.Since, params.Until)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{``, 0, "query is empty"},
+		{`color:red`, 0, "unknown field"},
+		{`tag meeting`, 3, "expected an operator"},
+		{`tag:`, 4, "expected a value"},
+		{`client:nobody`, 7, "unknown client"},
+		{`duration>soon`, 9, "invalid duration"},
+		{`tag>meeting`, 3, `">" can not be used with tag`},
+		{`desc~"(unclosed"`, 5, "invalid regular expression"},
+		{`desc:"unclosed`, 5, "unclosed quote"},
+		{`(tag:a or tag:b`, 0, "unclosed parenthesis"},
+		{`tag:a and`, 9, "expected a term"},
+		{`tag:a )`, 6, "unexpected"},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.query, env())
+		if err == nil {
+			t.Errorf("(%s) Expected an error", test.query)
+			continue
+		}
+		qerr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("(%s) Expected *Error, got %T", test.query, err)
+			continue
+		}
+		if qerr.Pos != test.pos || !strings.Contains(qerr.Msg, test.msg) {
+			t.Errorf("(%s) Expected %q at %d, got %q at %d", test.query, test.msg, test.pos, qerr.Msg, qerr.Pos)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hdoupe/ttrack/output"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// WritePDF renders a document as an A4 PDF. The logo is an optional
+// path to a PNG or JPEG image that is shown at the top of the first
+// page.
+func WritePDF(out io.Writer, doc Document, logo string) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AddPage()
+	width, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	content := width - left - right
+
+	if logo != "" {
+		pdf.ImageOptions(logo, width-right-40, 15, 40, 0, false, gofpdf.ImageOptions{ReadDpi: true}, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "", 22)
+	pdf.CellFormat(content, 12, tr(doc.Title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(119, 119, 119)
+	period := doc.Date.Format("January 2, 2006")
+	if !doc.Since.IsZero() {
+		until := doc.Until
+		if until.IsZero() {
+			until = doc.Date
+		}
+		period += fmt.Sprintf("  |  %s - %s", doc.Since.In(doc.Location).Format("Jan 2, 2006"), until.In(doc.Location).Format("Jan 2, 2006"))
+	}
+	pdf.CellFormat(content, 6, tr(period), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	// Sender and recipient side by side.
+	party := func(label string, p Party) string {
+		lines := []string{label, p.Name}
+		if strings.TrimSpace(p.Address) != "" {
+			lines = append(lines, strings.Split(strings.TrimSpace(p.Address), "\n")...)
+		}
+		if p.Email != "" {
+			lines = append(lines, p.Email)
+		}
+		return tr(strings.Join(lines, "\n"))
+	}
+	pdf.SetTextColor(34, 34, 34)
+	y := pdf.GetY()
+	pdf.MultiCell(content/2, 5, party("From", doc.From), "", "L", false)
+	fromY := pdf.GetY()
+	pdf.SetXY(left+content/2, y)
+	pdf.MultiCell(content/2, 5, party("To", doc.To), "", "L", false)
+	if fromY > pdf.GetY() {
+		pdf.SetY(fromY)
+	}
+	pdf.Ln(8)
+
+	table := func(header []string, widths []float64, aligns []string, rows [][]string) {
+		pdf.SetFont("Helvetica", "B", 10)
+		for ix, cell := range header {
+			pdf.CellFormat(widths[ix], 7, tr(cell), "B", 0, aligns[ix], false, 0, "")
+		}
+		pdf.Ln(-1)
+		for ix, row := range rows {
+			style, border := "", "B"
+			if ix == len(rows)-1 {
+				style, border = "B", "T"
+			}
+			pdf.SetFont("Helvetica", style, 10)
+			for jx, cell := range row {
+				text := tr(cell)
+				for pdf.GetStringWidth(text) > widths[jx]-2 && len(text) > 1 {
+					text = text[:len(text)-2] + "\x85"
+				}
+				pdf.CellFormat(widths[jx], 7, text, border, 0, aligns[jx], false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		pdf.Ln(6)
+	}
+
+	if doc.Kind == Invoice {
+		rows := [][]string{}
+		for _, item := range doc.Lines {
+			rows = append(rows, []string{
+				item.Name,
+				item.Description,
+				fmt.Sprintf("%.2f", item.Hours),
+				fmt.Sprintf("%.2f", item.Rate.Hourly),
+				fmt.Sprintf("%.2f", item.Amount()),
+			})
+		}
+		rows = append(rows, []string{"Total", "", "", "", strings.TrimSpace(fmt.Sprintf("%.2f %s", doc.Amount, doc.Currency))})
+		table(
+			[]string{"Item", "Description", "Hours", "Rate", "Amount"},
+			[]float64{28, content - 28 - 18 - 20 - 30, 18, 20, 30},
+			[]string{"L", "L", "R", "R", "R"},
+			rows,
+		)
+		pdf.SetFont("Helvetica", "", 14)
+		pdf.CellFormat(content, 10, "Time entries", "", 1, "L", false, 0, "")
+	}
+
+	header := []string{"Date", "Time", "Description", "Duration"}
+	widths := []float64{26, 26, content - 26 - 26 - 22, 22}
+	aligns := []string{"L", "L", "L", "R"}
+	if doc.Rounding {
+		header = append(header, "Billed")
+		widths[2] -= 22
+		widths = append(widths, 22)
+		aligns = append(aligns, "R")
+	}
+	rows := [][]string{}
+	for _, row := range doc.Rows {
+		finish := "-"
+		if !row.Finish.IsZero() {
+			finish = row.Finish.Format("15:04")
+		}
+		cells := []string{
+			row.Start.Format("Mon Jan 2"),
+			row.Start.Format("15:04") + "-" + finish,
+			row.Description,
+			output.FormatDuration(row.Duration),
+		}
+		if doc.Rounding {
+			cells = append(cells, output.FormatDuration(row.Rounded))
+		}
+		rows = append(rows, cells)
+	}
+	total := []string{fmt.Sprintf("Total (%.2f hours)", doc.Rounded.Hours()), "", "", output.FormatDuration(doc.Duration)}
+	if doc.Rounding {
+		total = append(total, output.FormatDuration(doc.Rounded))
+	}
+	table(header, widths, aligns, append(rows, total))
+
+	return pdf.Output(out)
+}
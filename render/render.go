@@ -0,0 +1,196 @@
+// Package render writes timesheets and invoices as standalone HTML and
+// PDF documents.
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/output"
+	"github.com/hdoupe/ttrack/track"
+)
+
+// Document kinds.
+const (
+	Timesheet = "timesheet"
+	Invoice   = "invoice"
+)
+
+// Kinds lists the documents that can be rendered.
+var Kinds = []string{Timesheet, Invoice}
+
+// Party is the sender or the recipient of a document.
+type Party struct {
+	Name    string
+	Address string
+	Email   string
+}
+
+// Row is one entry of a timesheet.
+type Row struct {
+	Start       time.Time
+	Finish      time.Time
+	Description string
+	Tags        []string
+	Duration    time.Duration
+	Rounded     time.Duration
+}
+
+// Document contains everything that is shown on a timesheet or an
+// invoice. Times are in Location.
+type Document struct {
+	Kind     string
+	Title    string
+	Date     time.Time
+	From     Party
+	To       Party
+	Client   track.Client
+	Since    time.Time
+	Until    time.Time
+	Rows     []Row
+	Lines    []track.LineItem
+	Duration time.Duration
+	Rounded  time.Duration
+	// Rounding is true if durations are rounded.
+	Rounding bool
+	Amount   float64
+	Currency string
+	Location *time.Location
+}
+
+// Options configures a document.
+type Options struct {
+	From     Party
+	Client   track.Client
+	Since    time.Time
+	Until    time.Time
+	Location *time.Location
+	Clients  []track.Client
+	Rounding track.RoundingPolicy
+}
+
+func newDocument(kind string, opts Options) Document {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	to := Party{Name: opts.Client.Name, Address: opts.Client.Address, Email: opts.Client.Email}
+	if to.Name == "" {
+		to.Name = opts.Client.Nickname
+	}
+	return Document{
+		Kind:     kind,
+		Title:    strings.Title(kind),
+		Date:     time.Now().In(opts.Location),
+		From:     opts.From,
+		To:       to,
+		Client:   opts.Client,
+		Since:    opts.Since,
+		Until:    opts.Until,
+		Rounding: !opts.Rounding.IsZero(),
+		Location: opts.Location,
+	}
+}
+
+// NewTimesheet creates a timesheet with a row for each entry.
+func NewTimesheet(entries []track.Entry, opts Options) Document {
+	doc := newDocument(Timesheet, opts)
+	for _, entry := range entries {
+		row := Row{
+			Start:       entry.StartedAt.In(doc.Location),
+			Description: entry.Description,
+			Tags:        entry.Tags,
+			Duration:    track.Elapsed(entry),
+			Rounded:     opts.Rounding.Rounded(opts.Clients, entry),
+		}
+		if !entry.InProgress() {
+			row.Finish = entry.FinishedAt.In(doc.Location)
+		}
+		doc.Rows = append(doc.Rows, row)
+		doc.Duration += row.Duration
+		doc.Rounded += row.Rounded
+	}
+	return doc
+}
+
+// NewInvoice creates an invoice document from the line items of an
+// invoice. The entries of the line items are listed as rows.
+func NewInvoice(invoice track.Invoice, opts Options) Document {
+	doc := NewTimesheet(invoice.Entries(), opts)
+	doc.Kind = Invoice
+	doc.Title = strings.Title(Invoice)
+	doc.Lines = invoice.Lines
+	doc.Amount = invoice.Total()
+	doc.Currency = invoice.Currency
+	return doc
+}
+
+// FindFile returns the path of a file in the template directory. Files
+// in a subdirectory named after the client's nickname are preferred.
+// An empty string is returned if the file does not exist.
+func FindFile(dir string, nickname string, name string) string {
+	if dir == "" {
+		return ""
+	}
+	candidates := []string{filepath.Join(dir, name)}
+	if nickname != "" {
+		candidates = append([]string{filepath.Join(dir, nickname, name)}, candidates...)
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Template returns the HTML template for a document. The template is
+// read from <dir>/<client nickname>/<kind>.html or <dir>/<kind>.html, and
+// the built-in template is used if neither exists. Templates can use
+// these functions:
+//
+//	date      formats a time, e.g. {{date "Jan 2, 2006" .Date}}
+//	duration  formats a duration, e.g. {{duration .Duration}}
+//	hours     a duration in decimal hours, e.g. {{hours .Rounded}}
+//	money     formats an amount, e.g. {{money .Amount}}
+//	lines     splits text into lines, e.g. {{range lines .From.Address}}
+func Template(dir string, doc Document) (*template.Template, error) {
+	text := defaultTemplates[doc.Kind]
+	if path := FindFile(dir, doc.Client.Nickname, doc.Kind+".html"); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return nil, fmt.Errorf("unknown document %q, use one of %s", doc.Kind, strings.Join(Kinds, ", "))
+	}
+	funcs := template.FuncMap{
+		"date": func(layout string, t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format(layout)
+		},
+		"duration": output.FormatDuration,
+		"hours":    func(d time.Duration) string { return fmt.Sprintf("%.2f", d.Hours()) },
+		"money":    func(amount float64) string { return fmt.Sprintf("%.2f", amount) },
+		"lines":    func(text string) []string { return strings.Split(strings.TrimSpace(text), "\n") },
+	}
+	return template.New(doc.Kind).Funcs(funcs).Parse(text)
+}
+
+// WriteHTML renders a document as a standalone HTML page.
+func WriteHTML(out io.Writer, doc Document, dir string) error {
+	tmpl, err := Template(dir, doc)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(out, doc)
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hdoupe/ttrack/track"
+)
+
+func mockEntries() []track.Entry {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	return []track.Entry{
+		{ID: 1, StartedAt: started, FinishedAt: started.Add(50 * time.Minute), Duration: 50 * 60, Description: "Write <code>", ClientID: 1, Billable: true},
+		{ID: 2, StartedAt: started.Add(2 * time.Hour), FinishedAt: started.Add(3 * time.Hour), Duration: 60 * 60, Description: "Review", ClientID: 1, Billable: true},
+	}
+}
+
+func options() Options {
+	client := track.Client{Nickname: "acme", ClientID: 1, Name: "Acme Corp", Address: "1 Road\nTown"}
+	return Options{
+		From:     Party{Name: "Jane Doe"},
+		Client:   client,
+		Location: time.UTC,
+		Clients:  []track.Client{client},
+		Rounding: track.RoundingPolicy{Default: track.Rounding{Mode: track.RoundUp, Increment: 15 * time.Minute}},
+	}
+}
+
+func TestNewTimesheet(t *testing.T) {
+	doc := NewTimesheet(mockEntries(), options())
+	if len(doc.Rows) != 2 || doc.Duration != 110*time.Minute || doc.Rounded != 2*time.Hour {
+		t.Errorf("Unexpected timesheet: %+v", doc)
+	}
+	if doc.To.Name != "Acme Corp" || !doc.Rounding {
+		t.Errorf("Expected client details and rounding, got %+v", doc)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	opts := options()
+	invoice, err := track.NewInvoice(mockEntries(), track.InvoiceOptions{
+		ClientID: 1,
+		Rates:    []track.Rate{{Hourly: 100, Currency: "USD"}},
+		Clients:  opts.Clients,
+		Rounding: opts.Rounding,
+		Location: time.UTC,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := NewInvoice(invoice, opts)
+
+	var b bytes.Buffer
+	if err := WriteHTML(&b, doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"<h1>Invoice</h1>", "Acme Corp", "1 Road<br>Town<br>", "200.00 USD", "Write &lt;code&gt;", "2h00m"} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("Expected HTML to contain %q, got:\n%s", expected, b.String())
+		}
+	}
+}
+
+func TestClientTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ttrack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "acme"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "acme", "timesheet.html"), []byte(`{{.To.Name}}: {{hours .Rounded}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "timesheet.html"), []byte(`default`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := WriteHTML(&b, NewTimesheet(mockEntries(), options()), dir); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "Acme Corp: 2.00" {
+		t.Errorf("Expected the client's template, got %q", b.String())
+	}
+
+	opts := options()
+	opts.Client = track.Client{Nickname: "globex"}
+	b.Reset()
+	if err := WriteHTML(&b, NewTimesheet(mockEntries(), opts), dir); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "default" {
+		t.Errorf("Expected the default template in the directory, got %q", b.String())
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	var b bytes.Buffer
+	if err := WritePDF(&b, NewTimesheet(mockEntries(), options()), ""); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "%PDF-") {
+		t.Errorf("Expected a PDF, got %q", b.String()[:20])
+	}
+}
@@ -0,0 +1,85 @@
+package render
+
+const templateHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}{{if .To.Name}} - {{.To.Name}}{{end}}</title>
+<style>
+  body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 2em auto; max-width: 50em; }
+  h1 { font-weight: normal; margin-bottom: 0.2em; }
+  .parties { display: flex; justify-content: space-between; margin: 2em 0; }
+  .muted { color: #777; }
+  table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+  th, td { text-align: left; padding: 0.4em 0.6em; border-bottom: 1px solid #ddd; vertical-align: top; }
+  th { border-bottom: 2px solid #222; }
+  td.number, th.number { text-align: right; white-space: nowrap; }
+  tr.total td { font-weight: bold; border-bottom: none; border-top: 2px solid #222; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="muted">{{date "January 2, 2006" .Date}}{{if not .Since.IsZero}} &middot; {{date "Jan 2, 2006" .Since}} &ndash; {{if .Until.IsZero}}{{date "Jan 2, 2006" .Date}}{{else}}{{date "Jan 2, 2006" .Until}}{{end}}{{end}}</div>
+<div class="parties">
+  <div>
+    <div class="muted">From</div>
+    <strong>{{.From.Name}}</strong><br>
+    {{range lines .From.Address}}{{.}}<br>{{end}}
+    {{.From.Email}}
+  </div>
+  <div>
+    <div class="muted">To</div>
+    <strong>{{.To.Name}}</strong><br>
+    {{range lines .To.Address}}{{.}}<br>{{end}}
+    {{.To.Email}}
+  </div>
+</div>
+`
+
+const templateRows = `<table>
+  <tr>
+    <th>Date</th><th>Time</th><th>Description</th>
+    <th class="number">Duration</th>{{if .Rounding}}<th class="number">Billed</th>{{end}}
+  </tr>
+  {{range .Rows}}
+  <tr>
+    <td>{{date "Mon Jan 2" .Start}}</td>
+    <td>{{date "15:04" .Start}}&ndash;{{date "15:04" .Finish}}</td>
+    <td>{{.Description}}</td>
+    <td class="number">{{duration .Duration}}</td>{{if $.Rounding}}<td class="number">{{duration .Rounded}}</td>{{end}}
+  </tr>
+  {{end}}
+  <tr class="total">
+    <td colspan="3">Total ({{hours .Rounded}} hours)</td>
+    <td class="number">{{duration .Duration}}</td>{{if .Rounding}}<td class="number">{{duration .Rounded}}</td>{{end}}
+  </tr>
+</table>
+`
+
+const templateFoot = `</body>
+</html>
+`
+
+var defaultTemplates = map[string]string{
+	Timesheet: templateHead + templateRows + templateFoot,
+	Invoice: templateHead + `<table>
+  <tr>
+    <th>Item</th><th>Description</th><th class="number">Hours</th><th class="number">Rate</th><th class="number">Amount</th>
+  </tr>
+  {{range .Lines}}
+  <tr>
+    <td>{{.Name}}</td>
+    <td>{{.Description}}</td>
+    <td class="number">{{printf "%.2f" .Hours}}</td>
+    <td class="number">{{money .Rate.Hourly}}</td>
+    <td class="number">{{money .Amount}}</td>
+  </tr>
+  {{end}}
+  <tr class="total">
+    <td colspan="4">Total</td>
+    <td class="number">{{money .Amount}} {{.Currency}}</td>
+  </tr>
+</table>
+<h2>Time entries</h2>
+` + templateRows + templateFoot,
+}
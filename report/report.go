@@ -0,0 +1,280 @@
+// Package report aggregates time entries into timesheets.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/timeexpr"
+	"github.com/hdoupe/ttrack/track"
+)
+
+// Groupings for Options.By.
+const (
+	Day     = "day"
+	Week    = "week"
+	Month   = "month"
+	Client  = "client"
+	Project = "project"
+	Tag     = "tag"
+)
+
+// Groupings lists the supported groupings.
+var Groupings = []string{Day, Week, Month, Client, Project, Tag}
+
+// Options configures how a report is built.
+type Options struct {
+	// By lists the groupings from the outermost to the innermost.
+	By []string
+	// Location is used to find the day, week and month of entries.
+	Location *time.Location
+	// WeekStart is the first day of the week. Weeks that start on Monday
+	// are named by their ISO week, e.g. 2021-W13, and other weeks by
+	// their first day.
+	WeekStart time.Weekday
+	// Clients are used to find the nicknames of clients.
+	Clients []track.Client
+	// Earnings adds the amount earned for billable entries using Rates.
+	Earnings bool
+	Rates    []track.Rate
+	// Rounding is used for the rounded durations and for earnings.
+	Rounding track.RoundingPolicy
+}
+
+// Group is the total time for the entries with the same key. Groups are
+// nested in the order of Options.By.
+type Group struct {
+	By       string
+	Key      string
+	Duration time.Duration
+	Rounded  time.Duration
+	// Percent of the report's total duration.
+	Percent  float64
+	Earnings track.Money
+	Entries  []track.Entry `json:"-"`
+	Groups   []Group
+}
+
+// Report contains the groups and the total duration of the entries.
+type Report struct {
+	By       []string
+	Duration time.Duration
+	Rounded  time.Duration
+	// Rounding is true if durations are rounded.
+	Rounding bool
+	Earnings track.Money
+	Entries  []track.Entry `json:"-"`
+	Groups   []Group
+	// Unrated lists the billable entries that do not have a rate when
+	// earnings are calculated.
+	Unrated []track.Entry `json:"-"`
+}
+
+// Build groups the entries. Entries with more than one tag are counted
+// in the group of each tag, so percentages of tag groups may add up to
+// more than 100.
+func Build(entries []track.Entry, opts Options) (Report, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	for _, by := range opts.By {
+		if !isGrouping(by) {
+			return Report{}, fmt.Errorf("unknown grouping %q, use one of %s", by, strings.Join(Groupings, ", "))
+		}
+	}
+	report := Report{
+		By:       opts.By,
+		Entries:  entries,
+		Duration: total(entries),
+		Rounded:  rounded(entries, opts),
+		Rounding: !opts.Rounding.IsZero(),
+	}
+	report.Groups = group(entries, opts.By, opts, report.Duration)
+	if opts.Earnings {
+		report.Earnings = earnings(entries, opts)
+		for _, entry := range entries {
+			if _, ok := track.RateFor(opts.Rates, opts.Clients, entry); entry.Billable && !ok {
+				report.Unrated = append(report.Unrated, entry)
+			}
+		}
+	}
+	return report, nil
+}
+
+func isGrouping(by string) bool {
+	for _, grouping := range Groupings {
+		if by == grouping {
+			return true
+		}
+	}
+	return false
+}
+
+func total(entries []track.Entry) time.Duration {
+	var res time.Duration
+	for _, entry := range entries {
+		res += track.Elapsed(entry)
+	}
+	return res
+}
+
+func rounded(entries []track.Entry, opts Options) time.Duration {
+	var res time.Duration
+	for _, entry := range entries {
+		res += opts.Rounding.Rounded(opts.Clients, entry)
+	}
+	return res
+}
+
+// earnings adds up the amounts earned for the billable entries using
+// their rounded durations.
+func earnings(entries []track.Entry, opts Options) track.Money {
+	res := track.Money{}
+	for _, entry := range entries {
+		if !entry.Billable {
+			continue
+		}
+		if rate, ok := track.RateFor(opts.Rates, opts.Clients, entry); ok {
+			res.Add(rate.Amount(opts.Rounding.Rounded(opts.Clients, entry)), rate.Currency)
+		}
+	}
+	return res
+}
+
+func percent(d time.Duration, of time.Duration) float64 {
+	if of == 0 {
+		return 0
+	}
+	return 100 * float64(d) / float64(of)
+}
+
+// Keys returns the keys of the entry for a grouping.
+func Keys(entry track.Entry, by string, opts Options) []string {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	started := entry.StartedAt.In(loc)
+	switch by {
+	case Day:
+		return []string{started.Format("2006-01-02")}
+	case Week:
+		start := timeexpr.StartOfWeek(started, opts.WeekStart)
+		if opts.WeekStart == time.Monday {
+			year, week := start.ISOWeek()
+			return []string{fmt.Sprintf("%d-W%02d", year, week)}
+		}
+		return []string{"week of " + start.Format("2006-01-02")}
+	case Month:
+		return []string{started.Format("2006-01")}
+	case Client:
+		if client, ok := track.ClientFor(opts.Clients, entry); ok {
+			return []string{client.Nickname}
+		}
+		if entry.ClientID == 0 {
+			return []string{"(none)"}
+		}
+		return []string{fmt.Sprintf("client %d", entry.ClientID)}
+	case Project:
+		if entry.ProjectID == 0 {
+			return []string{"(none)"}
+		}
+		return []string{fmt.Sprint(entry.ProjectID)}
+	case Tag:
+		if len(entry.Tags) == 0 {
+			return []string{"(untagged)"}
+		}
+		keys := []string{}
+		for _, tag := range entry.Tags {
+			keys = append(keys, strings.ToLower(tag))
+		}
+		return keys
+	}
+	return []string{}
+}
+
+func group(entries []track.Entry, by []string, opts Options, reportTotal time.Duration) []Group {
+	if len(by) == 0 {
+		return nil
+	}
+	index := map[string]int{}
+	groups := []Group{}
+	for _, entry := range entries {
+		for _, key := range Keys(entry, by[0], opts) {
+			ix, exists := index[key]
+			if !exists {
+				ix = len(groups)
+				index[key] = ix
+				groups = append(groups, Group{By: by[0], Key: key})
+			}
+			groups[ix].Entries = append(groups[ix].Entries, entry)
+		}
+	}
+	for ix := range groups {
+		groups[ix].Duration = total(groups[ix].Entries)
+		groups[ix].Rounded = rounded(groups[ix].Entries, opts)
+		groups[ix].Percent = percent(groups[ix].Duration, reportTotal)
+		groups[ix].Groups = group(groups[ix].Entries, by[1:], opts, reportTotal)
+		if opts.Earnings {
+			groups[ix].Earnings = earnings(groups[ix].Entries, opts)
+		}
+	}
+	switch by[0] {
+	case Day, Week, Month:
+		sort.SliceStable(groups, func(i int, j int) bool { return groups[i].Key < groups[j].Key })
+	default:
+		sort.SliceStable(groups, func(i int, j int) bool {
+			if groups[i].Duration == groups[j].Duration {
+				return groups[i].Key < groups[j].Key
+			}
+			return groups[i].Duration > groups[j].Duration
+		})
+	}
+	return groups
+}
+
+// Matrix is a paper style timesheet for one week with a row for each
+// client and a column for each day from the first day of the week.
+type Matrix struct {
+	Week   string
+	Days   []time.Time
+	Rows   []MatrixRow
+	Totals []time.Duration
+	Total  time.Duration
+}
+
+// MatrixRow is the time for one client on each day of the week.
+type MatrixRow struct {
+	Client string
+	Days   []time.Duration
+	Total  time.Duration
+}
+
+// Matrices builds a Matrix for each week with entries.
+func Matrices(entries []track.Entry, opts Options) []Matrix {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	res := []Matrix{}
+	for _, week := range group(entries, []string{Week, Client}, opts, total(entries)) {
+		start := timeexpr.StartOfWeek(week.Entries[0].StartedAt.In(opts.Location), opts.WeekStart)
+
+		matrix := Matrix{Week: week.Key, Totals: make([]time.Duration, 7), Total: week.Duration}
+		for day := 0; day < 7; day++ {
+			matrix.Days = append(matrix.Days, start.AddDate(0, 0, day))
+		}
+		for _, client := range week.Groups {
+			row := MatrixRow{Client: client.Key, Days: make([]time.Duration, 7), Total: client.Duration}
+			for _, entry := range client.Entries {
+				day := (int(entry.StartedAt.In(opts.Location).Weekday()) - int(opts.WeekStart) + 7) % 7
+				row.Days[day] += track.Elapsed(entry)
+				matrix.Totals[day] += track.Elapsed(entry)
+			}
+			matrix.Rows = append(matrix.Rows, row)
+		}
+		res = append(res, matrix)
+	}
+	return res
+}
@@ -0,0 +1,248 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hdoupe/ttrack/output"
+	"github.com/hdoupe/ttrack/track"
+)
+
+func mockEntries() []track.Entry {
+	// Tuesday, March 30 2021.
+	started := time.Date(2021, 3, 30, 14, 0, 0, 0, time.UTC)
+	entry := func(id int, start time.Time, d time.Duration, clientID int, tags ...string) track.Entry {
+		return track.Entry{
+			ID:          id,
+			StartedAt:   start,
+			FinishedAt:  start.Add(d),
+			Duration:    int(d.Seconds()),
+			Description: "Work",
+			ClientID:    clientID,
+			Tags:        tags,
+			Billable:    true,
+		}
+	}
+	return []track.Entry{
+		entry(1, started, 3*time.Hour, 1, "dev"),
+		entry(2, started.Add(4*time.Hour), time.Hour, 2, "dev", "review"),
+		entry(3, started.AddDate(0, 0, 1), 2*time.Hour, 1),
+		entry(4, started.AddDate(0, 0, 7), 2*time.Hour, 2, "review"),
+	}
+}
+
+func options(by ...string) Options {
+	return Options{
+		By:        by,
+		Location:  time.UTC,
+		WeekStart: time.Monday,
+		Clients:   []track.Client{{Nickname: "acme", ClientID: 1}, {Nickname: "globex", ClientID: 2}},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	res, err := Build(mockEntries(), options(Week, Client))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Duration != 8*time.Hour {
+		t.Errorf("Expected total of 8h, got %v", res.Duration)
+	}
+	if len(res.Groups) != 2 || res.Groups[0].Key != "2021-W13" || res.Groups[1].Key != "2021-W14" {
+		t.Fatalf("Expected weeks 2021-W13 and 2021-W14, got %v", res.Groups)
+	}
+	week := res.Groups[0]
+	if week.Duration != 6*time.Hour || week.Percent != 75 {
+		t.Errorf("Expected 6h and 75%%, got %v and %v", week.Duration, week.Percent)
+	}
+	if len(week.Groups) != 2 || week.Groups[0].Key != "acme" || week.Groups[0].Duration != 5*time.Hour {
+		t.Errorf("Expected acme with 5h first, got %v", week.Groups)
+	}
+	if week.Groups[1].Percent != 12.5 {
+		t.Errorf("Expected percent of the report total, got %v", week.Groups[1].Percent)
+	}
+}
+
+func TestBuildTags(t *testing.T) {
+	res, err := Build(mockEntries(), options(Tag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]time.Duration{"dev": 4 * time.Hour, "review": 3 * time.Hour, "(untagged)": 2 * time.Hour}
+	if len(res.Groups) != len(expected) {
+		t.Fatalf("Expected %d groups, got %v", len(expected), res.Groups)
+	}
+	for _, group := range res.Groups {
+		if group.Duration != expected[group.Key] {
+			t.Errorf("Expected %v for %s, got %v", expected[group.Key], group.Key, group.Duration)
+		}
+	}
+}
+
+func TestBuildUnknownGrouping(t *testing.T) {
+	if _, err := Build(mockEntries(), options("year")); err == nil {
+		t.Error("Expected an error for an unknown grouping.")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	res, err := Build(mockEntries(), options(Day, Client))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := Write(&b, res, output.CSV); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"day,client,duration,hours,percent",
+		"2021-03-30,,4h00m,4.00,50.0%",
+		"2021-03-30,acme,3h00m,3.00,37.5%",
+		"2021-03-30,globex,1h00m,1.00,12.5%",
+		"2021-03-31,,2h00m,2.00,25.0%",
+		"2021-03-31,acme,2h00m,2.00,25.0%",
+		"2021-04-06,,2h00m,2.00,25.0%",
+		"2021-04-06,globex,2h00m,2.00,25.0%",
+		"Total,,8h00m,8.00,100.0%",
+	}
+	if got := strings.TrimSpace(b.String()); got != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected CSV:\n%s", got)
+	}
+}
+
+func TestMatrices(t *testing.T) {
+	matrices := Matrices(mockEntries(), options())
+	if len(matrices) != 2 {
+		t.Fatalf("Expected 2 weeks, got %d", len(matrices))
+	}
+	matrix := matrices[0]
+	if !matrix.Days[0].Equal(time.Date(2021, 3, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the week to start on Monday, got %v", matrix.Days[0])
+	}
+	if matrix.Rows[0].Client != "acme" || matrix.Rows[0].Days[1] != 3*time.Hour || matrix.Rows[0].Days[2] != 2*time.Hour {
+		t.Errorf("Unexpected row: %v", matrix.Rows[0])
+	}
+	if matrix.Totals[1] != 4*time.Hour || matrix.Total != 6*time.Hour {
+		t.Errorf("Unexpected totals: %v %v", matrix.Totals, matrix.Total)
+	}
+}
+
+func TestDayInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := mockEntries()[:2]
+	// 22:30 in New York is after midnight in UTC.
+	entries[1].StartedAt = time.Date(2021, 3, 31, 2, 30, 0, 0, time.UTC)
+	entries[1].FinishedAt = entries[1].StartedAt.Add(time.Hour)
+
+	for _, test := range []struct {
+		loc  *time.Location
+		keys []string
+	}{
+		{time.UTC, []string{"2021-03-30", "2021-03-31"}},
+		{loc, []string{"2021-03-30"}},
+	} {
+		opts := options(Day)
+		opts.Location = test.loc
+		res, err := Build(entries, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := []string{}
+		for _, group := range res.Groups {
+			keys = append(keys, group.Key)
+		}
+		if strings.Join(keys, ",") != strings.Join(test.keys, ",") {
+			t.Errorf("(%v) expected days %v, got %v", test.loc, test.keys, keys)
+		}
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	entries := mockEntries()
+	// Sunday, April 4 2021 is in ISO week 13 but starts a new week when
+	// weeks start on Sunday.
+	sunday := entries[0]
+	sunday.ID = 5
+	sunday.StartedAt = time.Date(2021, 4, 4, 10, 0, 0, 0, time.UTC)
+	sunday.FinishedAt = sunday.StartedAt.Add(time.Hour)
+	sunday.Duration = 3600
+	entries = append(entries, sunday)
+
+	opts := options(Week)
+	opts.WeekStart = time.Sunday
+	res, err := Build(entries, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Groups) != 2 || res.Groups[0].Key != "week of 2021-03-28" || res.Groups[0].Duration != 6*time.Hour ||
+		res.Groups[1].Key != "week of 2021-04-04" || res.Groups[1].Duration != 3*time.Hour {
+		t.Errorf("Expected weeks starting on March 28 and April 4, got %v", res.Groups)
+	}
+
+	matrices := Matrices(entries, opts)
+	if len(matrices) != 2 || !matrices[1].Days[0].Equal(sunday.StartedAt.Truncate(24*time.Hour)) {
+		t.Fatalf("Expected the second week to start on Sunday, got %v", matrices)
+	}
+	if matrices[1].Totals[0] != time.Hour || matrices[1].Totals[2] != 2*time.Hour {
+		t.Errorf("Expected Sunday and Tuesday totals, got %v", matrices[1].Totals)
+	}
+}
+
+func TestBuildEarnings(t *testing.T) {
+	entries := mockEntries()
+	entries[2].Billable = false
+	opts := options(Client)
+	opts.Earnings = true
+	opts.Rates = []track.Rate{{Client: "acme", Hourly: 100, Currency: "USD"}}
+	res, err := Build(entries, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Earnings["USD"] != 300 {
+		t.Errorf("Expected 300 USD, got %v", res.Earnings)
+	}
+	if len(res.Unrated) != 2 {
+		t.Errorf("Expected the globex entries to be unrated, got %v", res.Unrated)
+	}
+
+	var b bytes.Buffer
+	if err := Write(&b, res, output.CSV); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "acme,5h00m,5.00,62.5%,300.00 USD") {
+		t.Errorf("Expected earnings for acme, got:\n%s", b.String())
+	}
+}
+
+func TestBuildRounding(t *testing.T) {
+	entries := mockEntries()
+	entries[1].FinishedAt = entries[1].StartedAt.Add(50 * time.Minute)
+	entries[1].Duration = 50 * 60
+	opts := options(Client)
+	opts.Earnings = true
+	opts.Rates = []track.Rate{{Hourly: 100, Currency: "USD"}}
+	opts.Rounding = track.RoundingPolicy{Default: track.Rounding{Mode: track.RoundUp, Increment: 15 * time.Minute}}
+	res, err := Build(entries, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Rounding || res.Duration != 7*time.Hour+50*time.Minute || res.Rounded != 8*time.Hour {
+		t.Errorf("Expected 7h50m rounded to 8h, got %v and %v", res.Duration, res.Rounded)
+	}
+	if res.Earnings["USD"] != 800 {
+		t.Errorf("Expected earnings from rounded durations, got %v", res.Earnings)
+	}
+
+	var b bytes.Buffer
+	if err := Write(&b, res, output.CSV); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "client,duration,hours,rounded,rounded_hours,percent,earnings\n") {
+		t.Errorf("Unexpected header:\n%s", b.String())
+	}
+}
@@ -0,0 +1,257 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/output"
+	"github.com/hdoupe/ttrack/track"
+)
+
+// Formats lists the output formats supported by Write and WriteMatrices.
+var Formats = []string{output.Table, output.CSV, output.TSV, output.Markdown, output.JSON}
+
+type jsonGroup struct {
+	By       string      `json:"by"`
+	Key      string      `json:"key"`
+	Duration string      `json:"duration"`
+	Hours    float64     `json:"hours"`
+	Rounded  string      `json:"rounded,omitempty"`
+	RHours   float64     `json:"rounded_hours,omitempty"`
+	Percent  float64     `json:"percent"`
+	Earnings track.Money `json:"earnings,omitempty"`
+	Entries  int         `json:"entries"`
+	Groups   []jsonGroup `json:"groups,omitempty"`
+}
+
+type jsonReport struct {
+	By       []string    `json:"by"`
+	Duration string      `json:"duration"`
+	Hours    float64     `json:"hours"`
+	Rounded  string      `json:"rounded,omitempty"`
+	RHours   float64     `json:"rounded_hours,omitempty"`
+	Earnings track.Money `json:"earnings,omitempty"`
+	Entries  int         `json:"entries"`
+	Groups   []jsonGroup `json:"groups"`
+}
+
+func hours(d time.Duration) float64 {
+	return float64(int64(d.Hours()*100+0.5)) / 100
+}
+
+// cents rounds amounts to cents.
+func cents(money track.Money) track.Money {
+	if money == nil {
+		return nil
+	}
+	res := track.Money{}
+	for currency, amount := range money {
+		res[currency] = math.Round(amount*100) / 100
+	}
+	return res
+}
+
+func toJSON(groups []Group, rounding bool) []jsonGroup {
+	res := []jsonGroup{}
+	for _, group := range groups {
+		value := jsonGroup{
+			By:       group.By,
+			Key:      group.Key,
+			Duration: output.FormatDuration(group.Duration),
+			Hours:    hours(group.Duration),
+			Percent:  float64(int64(group.Percent*10+0.5)) / 10,
+			Earnings: cents(group.Earnings),
+			Entries:  len(group.Entries),
+			Groups:   toJSON(group.Groups, rounding),
+		}
+		if rounding {
+			value.Rounded = output.FormatDuration(group.Rounded)
+			value.RHours = hours(group.Rounded)
+		}
+		res = append(res, value)
+	}
+	return res
+}
+
+// Write writes the report as an indented table, as CSV, TSV or
+// Markdown with a column for each grouping, or as JSON. Each group is
+// followed by its subgroups and the report ends with the total.
+func Write(out io.Writer, report Report, format string) error {
+	if format == output.JSON {
+		value := jsonReport{
+			By:       report.By,
+			Duration: output.FormatDuration(report.Duration),
+			Hours:    hours(report.Duration),
+			Earnings: cents(report.Earnings),
+			Entries:  len(report.Entries),
+			Groups:   toJSON(report.Groups, report.Rounding),
+		}
+		if report.Rounding {
+			value.Rounded = output.FormatDuration(report.Rounded)
+			value.RHours = hours(report.Rounded)
+		}
+		return output.WriteJSON(out, value, format)
+	}
+
+	var header []string
+	if format == output.Table {
+		header = []string{strings.Title(strings.Join(report.By, " / "))}
+	} else {
+		header = append(header, report.By...)
+	}
+	header = append(header, "Duration", "Hours")
+	if report.Rounding {
+		header = append(header, "Rounded", "Rounded Hours")
+	}
+	header = append(header, "Percent")
+	if report.Earnings != nil {
+		header = append(header, "Earnings")
+	}
+	if format != output.Table {
+		for ix := range header {
+			header[ix] = strings.ReplaceAll(strings.ToLower(header[ix]), " ", "_")
+		}
+	}
+
+	rows := [][]string{}
+	var walk func(groups []Group, path []string)
+	walk = func(groups []Group, path []string) {
+		for _, group := range groups {
+			keys := append(append([]string{}, path...), group.Key)
+			row := []string{}
+			if format == output.Table {
+				row = append(row, strings.Repeat("  ", len(path))+group.Key)
+			} else {
+				row = append(row, keys...)
+				row = append(row, make([]string, len(report.By)-len(keys))...)
+			}
+			rows = append(rows, append(row, report.columns(group.Duration, group.Rounded, group.Percent, group.Earnings)...))
+			walk(group.Groups, keys)
+		}
+	}
+	walk(report.Groups, nil)
+
+	total := []string{"Total"}
+	if format != output.Table {
+		total = make([]string, len(report.By))
+		if len(total) > 0 {
+			total[0] = "Total"
+		}
+	}
+	percent := 0.0
+	if report.Duration > 0 {
+		percent = 100
+	}
+	rows = append(rows, append(total, report.columns(report.Duration, report.Rounded, percent, report.Earnings)...))
+	return output.WriteRows(out, format, header, rows)
+}
+
+// columns formats the totals of a group.
+func (report *Report) columns(duration time.Duration, rounded time.Duration, percent float64, earnings track.Money) []string {
+	res := []string{output.FormatDuration(duration), fmt.Sprintf("%.2f", hours(duration))}
+	if report.Rounding {
+		res = append(res, output.FormatDuration(rounded), fmt.Sprintf("%.2f", hours(rounded)))
+	}
+	res = append(res, fmt.Sprintf("%.1f%%", percent))
+	if report.Earnings != nil {
+		res = append(res, earnings.String())
+	}
+	return res
+}
+
+type jsonMatrixRow struct {
+	Client string    `json:"client"`
+	Hours  []float64 `json:"hours"`
+	Total  float64   `json:"total"`
+}
+
+type jsonMatrix struct {
+	Week   string          `json:"week"`
+	Days   []string        `json:"days"`
+	Rows   []jsonMatrixRow `json:"rows"`
+	Totals []float64       `json:"totals"`
+	Total  float64         `json:"total"`
+}
+
+func hoursList(durations []time.Duration) []float64 {
+	res := []float64{}
+	for _, d := range durations {
+		res = append(res, hours(d))
+	}
+	return res
+}
+
+// WriteMatrices writes a day by client table for each week.
+func WriteMatrices(out io.Writer, matrices []Matrix, format string) error {
+	if format == output.JSON {
+		values := []jsonMatrix{}
+		for _, matrix := range matrices {
+			value := jsonMatrix{Week: matrix.Week, Totals: hoursList(matrix.Totals), Total: hours(matrix.Total)}
+			for _, day := range matrix.Days {
+				value.Days = append(value.Days, day.Format("2006-01-02"))
+			}
+			for _, row := range matrix.Rows {
+				value.Rows = append(value.Rows, jsonMatrixRow{Client: row.Client, Hours: hoursList(row.Days), Total: hours(row.Total)})
+			}
+			values = append(values, value)
+		}
+		return output.WriteJSON(out, values, format)
+	}
+
+	cell := func(d time.Duration) string {
+		if format == output.Table && d == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", hours(d))
+	}
+	if format != output.Table {
+		// One table with a column for the week and a column for each
+		// weekday.
+		header := []string{"week", "client", "mon", "tue", "wed", "thu", "fri", "sat", "sun", "total"}
+		rows := [][]string{}
+		for _, matrix := range matrices {
+			for _, row := range matrix.Rows {
+				cells := []string{matrix.Week, row.Client}
+				for _, d := range row.Days {
+					cells = append(cells, cell(d))
+				}
+				rows = append(rows, append(cells, cell(row.Total)))
+			}
+		}
+		return output.WriteRows(out, format, header, rows)
+	}
+
+	for ix, matrix := range matrices {
+		if ix > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out, matrix.Week)
+		header := []string{"Client"}
+		for _, day := range matrix.Days {
+			header = append(header, day.Format("Mon 01-02"))
+		}
+		header = append(header, "Total")
+
+		rows := [][]string{}
+		for _, row := range matrix.Rows {
+			cells := []string{row.Client}
+			for _, d := range row.Days {
+				cells = append(cells, cell(d))
+			}
+			rows = append(rows, append(cells, cell(row.Total)))
+		}
+		totals := []string{"Total"}
+		for _, d := range matrix.Totals {
+			totals = append(totals, cell(d))
+		}
+		rows = append(rows, append(totals, cell(matrix.Total)))
+
+		if err := output.WriteRows(out, format, header, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
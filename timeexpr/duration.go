@@ -0,0 +1,138 @@
+package timeexpr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Span is a length of time that may include calendar months and days.
+// Months and days follow the calendar and daylight saving time changes.
+// Adding months to the end of a month ends at the end of the target
+// month, e.g. March 31 minus one month is February 28.
+type Span struct {
+	Months   int
+	Days     int
+	Duration time.Duration
+}
+
+// Add returns t plus the span.
+func (span Span) Add(t time.Time) time.Time {
+	res := t.AddDate(0, span.Months, 0)
+	if res.Day() != t.Day() {
+		// The day does not exist in the target month.
+		res = res.AddDate(0, 0, -res.Day())
+	}
+	return res.AddDate(0, 0, span.Days).Add(span.Duration)
+}
+
+// Neg returns the span with the opposite sign.
+func (span Span) Neg() Span {
+	return Span{Months: -span.Months, Days: -span.Days, Duration: -span.Duration}
+}
+
+// FixedDuration converts the span to a time.Duration. Days are 24 hours
+// long. An error is returned if the span includes months.
+func (span Span) FixedDuration() (time.Duration, error) {
+	if span.Months != 0 {
+		return 0, fmt.Errorf("months do not have a fixed duration")
+	}
+	return time.Duration(span.Days)*24*time.Hour + span.Duration, nil
+}
+
+var (
+	clockDuration = regexp.MustCompile(`^(\d+):(\d{2})(?::(\d{2}))?$`)
+	spanPart      = regexp.MustCompile(`^(\d+(?:\.\d+)?|\.\d+)(mo|ns|us|µs|ms|w|d|h|m|s)`)
+)
+
+var spanUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// ParseSpan parses lengths of time like "1h30m", "1.5h", "2d", "1w",
+// "1mo", "1w2d" or "1:30". Days and weeks may be decimals, e.g. "1.5d",
+// and are added to the calendar if they are whole numbers. Months must
+// be whole numbers. A leading "-" or "+" sets the sign.
+func ParseSpan(s string) (Span, error) {
+	orig := s
+	s = strings.ToLower(strings.TrimSpace(s))
+	sign := 1
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = strings.TrimSpace(s[1:])
+	}
+	if s == "" {
+		return Span{}, fmt.Errorf("invalid duration %q", orig)
+	}
+
+	var span Span
+	if m := clockDuration.FindStringSubmatch(s); m != nil {
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		seconds, _ := strconv.Atoi(m[3])
+		if minutes > 59 || seconds > 59 {
+			return Span{}, fmt.Errorf("invalid duration %q, minutes and seconds must be less than 60", orig)
+		}
+		span.Duration = time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	} else {
+		for rest := s; rest != ""; {
+			m := spanPart.FindStringSubmatch(rest)
+			if m == nil {
+				return Span{}, fmt.Errorf("invalid duration %q, use units like 1mo, 1w, 2d, 1h30m, 1.5h or 1:30", orig)
+			}
+			rest = rest[len(m[0]):]
+			value, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				return Span{}, fmt.Errorf("invalid duration %q: %v", orig, err)
+			}
+			switch m[2] {
+			case "mo":
+				if value != float64(int(value)) {
+					return Span{}, fmt.Errorf("invalid duration %q, months must be whole numbers", orig)
+				}
+				span.Months += int(value)
+			case "w", "d":
+				days := value
+				if m[2] == "w" {
+					days *= 7
+				}
+				if days == float64(int(days)) {
+					span.Days += int(days)
+				} else {
+					span.Duration += time.Duration(days * float64(24*time.Hour))
+				}
+			default:
+				span.Duration += time.Duration(value * float64(spanUnits[m[2]]))
+			}
+		}
+	}
+	if sign < 0 {
+		span = span.Neg()
+	}
+	return span, nil
+}
+
+// ParseDuration parses lengths of time like ParseSpan and converts them
+// to a time.Duration where days are 24 hours long. Months are not
+// allowed.
+func ParseDuration(s string) (time.Duration, error) {
+	span, err := ParseSpan(s)
+	if err != nil {
+		return 0, err
+	}
+	d, err := span.FixedDuration()
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %v", s, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,117 @@
+package timeexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSpan(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Span
+	}{
+		{"90s", Span{Duration: 90 * time.Second}},
+		{"1h30m", Span{Duration: 90 * time.Minute}},
+		{"1.5h", Span{Duration: 90 * time.Minute}},
+		{".5h", Span{Duration: 30 * time.Minute}},
+		{"1:30", Span{Duration: 90 * time.Minute}},
+		{"0:45:30", Span{Duration: 45*time.Minute + 30*time.Second}},
+		{"2d", Span{Days: 2}},
+		{"1.5d", Span{Duration: 36 * time.Hour}},
+		{"1w", Span{Days: 7}},
+		{"1w2d3h", Span{Days: 9, Duration: 3 * time.Hour}},
+		{"1mo", Span{Months: 1}},
+		{"2MO1w", Span{Months: 2, Days: 7}},
+		{"-1h", Span{Duration: -time.Hour}},
+		{"-1mo2d", Span{Months: -1, Days: -2}},
+		{"250ms", Span{Duration: 250 * time.Millisecond}},
+	}
+	for _, test := range tests {
+		got, err := ParseSpan(test.s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.s, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("%q: expected %+v, got %+v", test.s, test.expected, got)
+		}
+	}
+
+	for _, s := range []string{"", "-", "1", "1x", "h", "1.5mo", "1:60", "1h 30m", "1:3"} {
+		if got, err := ParseSpan(s); err == nil {
+			t.Errorf("%q: expected an error, got %+v", s, got)
+		}
+	}
+}
+
+func TestParseDurationUnits(t *testing.T) {
+	tests := map[string]time.Duration{
+		"1d":    24 * time.Hour,
+		"1w":    7 * 24 * time.Hour,
+		"1d12h": 36 * time.Hour,
+		"2:15":  135 * time.Minute,
+	}
+	for s, expected := range tests {
+		got, err := ParseDuration(s)
+		if err != nil || got != expected {
+			t.Errorf("%q: expected %v, got %v (%v)", s, expected, got, err)
+		}
+	}
+	if _, err := ParseDuration("1mo"); err == nil {
+		t.Error("Expected an error for months.")
+	}
+}
+
+func TestSpanAdd(t *testing.T) {
+	loc := time.FixedZone("EDT", -4*60*60)
+	t0 := time.Date(2021, 3, 31, 9, 0, 0, 0, loc)
+	tests := []struct {
+		span     Span
+		expected time.Time
+	}{
+		{Span{Months: -1}, time.Date(2021, 2, 28, 9, 0, 0, 0, loc)},
+		{Span{Months: 1}, time.Date(2021, 4, 30, 9, 0, 0, 0, loc)},
+		{Span{Months: -1, Days: -1}, time.Date(2021, 2, 27, 9, 0, 0, 0, loc)},
+		{Span{Days: 1, Duration: time.Hour}, time.Date(2021, 4, 1, 10, 0, 0, 0, loc)},
+	}
+	for _, test := range tests {
+		if got := test.span.Add(t0); !got.Equal(test.expected) {
+			t.Errorf("%+v: expected %v, got %v", test.span, test.expected, got)
+		}
+	}
+}
+
+func TestPeriodRange(t *testing.T) {
+	// Wednesday
+	now := time.Date(2021, 3, 31, 14, 20, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2021, month, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		period    string
+		weekStart time.Weekday
+		since     time.Time
+		until     time.Time
+	}{
+		{Today, time.Monday, day(3, 31), day(4, 1)},
+		{Yesterday, time.Monday, day(3, 30), day(3, 31)},
+		{ThisWeek, time.Monday, day(3, 29), day(4, 5)},
+		{ThisWeek, time.Sunday, day(3, 28), day(4, 4)},
+		{ThisWeek, time.Wednesday, day(3, 31), day(4, 7)},
+		{LastWeek, time.Monday, day(3, 22), day(3, 29)},
+		{ThisMonth, time.Monday, day(3, 1), day(4, 1)},
+		{LastMonth, time.Monday, day(2, 1), day(3, 1)},
+		{LastYear, time.Monday, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), day(1, 1)},
+	}
+	for _, test := range tests {
+		since, until, err := PeriodRange(test.period, now, test.weekStart)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.period, err)
+			continue
+		}
+		if !since.Equal(test.since) || !until.Equal(test.until) {
+			t.Errorf("%s (%v): expected %v to %v, got %v to %v", test.period, test.weekStart, test.since, test.until, since, until)
+		}
+	}
+	if _, _, err := PeriodRange("next-week", now, time.Monday); err == nil {
+		t.Error("Expected an error for an unknown period.")
+	}
+}
@@ -0,0 +1,74 @@
+package timeexpr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar periods.
+const (
+	Today     = "today"
+	Yesterday = "yesterday"
+	ThisWeek  = "this-week"
+	LastWeek  = "last-week"
+	ThisMonth = "this-month"
+	LastMonth = "last-month"
+	ThisYear  = "this-year"
+	LastYear  = "last-year"
+)
+
+// Periods lists the calendar periods accepted by PeriodRange.
+var Periods = []string{Today, Yesterday, ThisWeek, LastWeek, ThisMonth, LastMonth, ThisYear, LastYear}
+
+// ParseWeekday parses a weekday name like "monday" or "mon".
+func ParseWeekday(s string) (time.Weekday, error) {
+	weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("invalid weekday %q", s)
+	}
+	return weekday, nil
+}
+
+// StartOfDay returns midnight at the start of t's day in t's location.
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns midnight at the start of the week containing t.
+// Weeks start on weekStart.
+func StartOfWeek(t time.Time, weekStart time.Weekday) time.Time {
+	return previous(StartOfDay(t), weekStart, false)
+}
+
+// PeriodRange returns the start of a calendar period and the start of
+// the period after it. Periods are resolved in now's location and weeks
+// start on weekStart.
+func PeriodRange(period string, now time.Time, weekStart time.Weekday) (time.Time, time.Time, error) {
+	today := StartOfDay(now)
+	switch strings.ToLower(period) {
+	case Today:
+		return today, today.AddDate(0, 0, 1), nil
+	case Yesterday:
+		return today.AddDate(0, 0, -1), today, nil
+	case ThisWeek:
+		start := StartOfWeek(now, weekStart)
+		return start, start.AddDate(0, 0, 7), nil
+	case LastWeek:
+		start := StartOfWeek(now, weekStart)
+		return start.AddDate(0, 0, -7), start, nil
+	case ThisMonth:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 1, 0), nil
+	case LastMonth:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return start.AddDate(0, -1, 0), start, nil
+	case ThisYear:
+		start := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(1, 0, 0), nil
+	case LastYear:
+		start := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+		return start.AddDate(-1, 0, 0), start, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q, use one of %s", period, strings.Join(Periods, ", "))
+}
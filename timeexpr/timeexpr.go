@@ -0,0 +1,351 @@
+// Package timeexpr parses the absolute and relative time expressions,
+// durations and calendar periods that are accepted on the command line,
+// e.g. "yesterday 3pm", "mon 9:30", "-15m", "now-1h", "last friday",
+// "2021-03-30T15:04-04:00", "1w2d", "1.5h" or "this-week".
+package timeexpr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parser resolves time expressions relative to the time returned by Now
+// and in Location.
+type Parser struct {
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+	// Location is used for expressions without an offset. Defaults to
+	// time.Local.
+	Location *time.Location
+	// ParseDuration parses the duration of relative expressions like
+	// "-15m". Defaults to ParseSpan, which also accepts calendar days,
+	// weeks and months.
+	ParseDuration func(string) (time.Duration, error)
+}
+
+// Examples lists expressions that are accepted by Parse.
+var Examples = []string{
+	"now", "15:30", "3pm", "yesterday 3pm", "mon 9:30", "last friday",
+	"-15m", "now-1h", "-1w", "03-30 9:00", "mar 30", "2021-03-30", "2021-03-30T15:04:05-04:00",
+}
+
+var isoLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+var relative = regexp.MustCompile(`^(now)?\s*([+-])\s*(\S+)$`)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{}
+
+func init() {
+	for month := time.January; month <= time.December; month++ {
+		name := strings.ToLower(month.String())
+		months[name] = month
+		months[name[:3]] = month
+	}
+	months["sept"] = time.September
+}
+
+func (p *Parser) now() time.Time {
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	return now().In(p.location())
+}
+
+func (p *Parser) location() *time.Location {
+	if p.Location == nil {
+		return time.Local
+	}
+	return p.Location
+}
+
+func (p *Parser) parseSpan(s string) (Span, error) {
+	if p.ParseDuration != nil {
+		d, err := p.ParseDuration(s)
+		return Span{Duration: d}, err
+	}
+	return ParseSpan(s)
+}
+
+// Parse resolves a time expression. Expressions with a date and no time
+// resolve to midnight, expressions with a time and no date resolve to
+// today, and dates without a year resolve to the current year.
+func (p *Parser) Parse(expr string) (time.Time, error) {
+	s := strings.TrimSpace(expr)
+	lower := strings.ToLower(s)
+	now := p.now()
+	if lower == "" {
+		return time.Time{}, p.errorf(expr, "empty time")
+	}
+	if lower == "now" {
+		return now, nil
+	}
+	if m := relative.FindStringSubmatch(lower); m != nil {
+		span, err := p.parseSpan(m[3])
+		if err != nil {
+			return time.Time{}, p.errorf(expr, "%v", err)
+		}
+		if m[2] == "-" {
+			span = span.Neg()
+		}
+		return span.Add(now), nil
+	}
+	for _, layout := range isoLayouts {
+		if t, err := time.ParseInLocation(layout, s, p.location()); err == nil {
+			return t, nil
+		}
+	}
+
+	tokens := tokenize(s)
+	var (
+		date    time.Time
+		hasDate bool
+		clock   time.Duration
+		hasTime bool
+		loc     = p.location()
+	)
+	setDate := func(t time.Time) error {
+		if hasDate {
+			return fmt.Errorf("more than one date")
+		}
+		date, hasDate = t, true
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, p.location())
+
+	for ix := 0; ix < len(tokens); ix++ {
+		token := tokens[ix]
+		word := strings.ToLower(token)
+		var err error
+		switch {
+		case word == "today":
+			err = setDate(today)
+		case word == "yesterday":
+			err = setDate(today.AddDate(0, 0, -1))
+		case word == "tomorrow":
+			err = setDate(today.AddDate(0, 0, 1))
+		case word == "last" || word == "this":
+			if ix+1 >= len(tokens) {
+				return time.Time{}, p.errorf(expr, "expected a weekday after %q", token)
+			}
+			weekday, ok := weekdays[strings.ToLower(tokens[ix+1])]
+			if !ok {
+				return time.Time{}, p.errorf(expr, "expected a weekday after %q, got %q", token, tokens[ix+1])
+			}
+			ix++
+			err = setDate(previous(today, weekday, word == "last"))
+		case isWeekday(word):
+			err = setDate(previous(today, weekdays[word], false))
+		case isMonth(word):
+			if ix+1 >= len(tokens) {
+				return time.Time{}, p.errorf(expr, "expected a day after %q", token)
+			}
+			day, convErr := strconv.Atoi(tokens[ix+1])
+			if convErr != nil {
+				return time.Time{}, p.errorf(expr, "expected a day after %q, got %q", token, tokens[ix+1])
+			}
+			ix++
+			year := now.Year()
+			if ix+1 < len(tokens) && len(tokens[ix+1]) == 4 {
+				if y, convErr := strconv.Atoi(tokens[ix+1]); convErr == nil {
+					year = y
+					ix++
+				}
+			}
+			err = setDate(p.date(year, months[word], day))
+		case word == "noon" || word == "midnight":
+			if hasTime {
+				return time.Time{}, p.errorf(expr, "more than one time")
+			}
+			hasTime = true
+			if word == "noon" {
+				clock = 12 * time.Hour
+			}
+		default:
+			if t, ok := parseDate(token, now.Year(), p.location()); ok {
+				err = setDate(t)
+			} else if c, ok := parseClock(word); ok {
+				if hasTime {
+					return time.Time{}, p.errorf(expr, "more than one time")
+				}
+				clock, hasTime = c, true
+			} else if z, ok := p.zone(token, now); ok && ix == len(tokens)-1 && (hasTime || hasDate) {
+				loc = z
+			} else {
+				return time.Time{}, p.errorf(expr, "unexpected %q", token)
+			}
+		}
+		if err != nil {
+			return time.Time{}, p.errorf(expr, "%v", err)
+		}
+	}
+	if !hasDate && !hasTime {
+		return time.Time{}, p.errorf(expr, "no date or time")
+	}
+	if !hasDate {
+		date = today
+	}
+	if date.IsZero() {
+		return time.Time{}, p.errorf(expr, "invalid date")
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc).Add(clock), nil
+}
+
+func (p *Parser) errorf(expr string, format string, args ...interface{}) error {
+	return fmt.Errorf(
+		"unable to parse time %q: %s (e.g. %s)",
+		expr,
+		fmt.Sprintf(format, args...),
+		strings.Join(Examples, ", "),
+	)
+}
+
+// date returns a zero time if the day does not exist in the month.
+func (p *Parser) date(year int, month time.Month, day int) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, p.location())
+	if t.Day() != day {
+		return time.Time{}
+	}
+	return t
+}
+
+// tokenize splits an expression on spaces and joins "am" and "pm" to
+// the time before them, e.g. "3:04 PM" becomes "3:04pm".
+func tokenize(s string) []string {
+	tokens := []string{}
+	for _, field := range strings.Fields(s) {
+		word := strings.ToLower(field)
+		if (word == "am" || word == "pm") && len(tokens) > 0 {
+			tokens[len(tokens)-1] += word
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+func isWeekday(word string) bool {
+	_, ok := weekdays[word]
+	return ok
+}
+
+func isMonth(word string) bool {
+	_, ok := months[word]
+	return ok
+}
+
+// previous returns the most recent weekday on or before day, or strictly
+// before day if before is true.
+func previous(day time.Time, weekday time.Weekday, before bool) time.Time {
+	days := (int(day.Weekday()) - int(weekday) + 7) % 7
+	if days == 0 && before {
+		days = 7
+	}
+	return day.AddDate(0, 0, -days)
+}
+
+var dateLayouts = []struct {
+	layout string
+	year   bool
+}{
+	{"2006-01-02", true},
+	{"2006/01/02", true},
+	{"1/2/2006", true},
+	{"01-02", false},
+	{"1/2", false},
+}
+
+// parseDate parses a date. Dates without a year are in year.
+func parseDate(token string, year int, loc *time.Location) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout.layout, token, loc)
+		if err != nil {
+			continue
+		}
+		if !layout.year {
+			day := t.Day()
+			t = time.Date(year, t.Month(), day, 0, 0, 0, 0, loc)
+			if t.Day() != day {
+				// February 29 in a year that is not a leap year.
+				return time.Time{}, false
+			}
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?(am|pm)?$`)
+
+// parseClock parses 24-hour times like "15:30" and "9:30:05", and
+// 12-hour times like "3pm" and "3:04pm". Hours without minutes require
+// am or pm.
+func parseClock(word string) (time.Duration, bool) {
+	m := clockPattern.FindStringSubmatch(word)
+	if m == nil || (m[2] == "" && m[4] == "") {
+		return 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	second, _ := strconv.Atoi(m[3])
+	if m[4] != "" {
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		hour = hour % 12
+		if m[4] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return 0, false
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second, true
+}
+
+var offsetPattern = regexp.MustCompile(`^([+-])(\d{2}):?(\d{2})$`)
+
+// zone parses a trailing time zone: UTC, Z, a numeric offset like -04:00,
+// or the abbreviation of the parser's location, e.g. EDT.
+func (p *Parser) zone(token string, now time.Time) (*time.Location, bool) {
+	upper := strings.ToUpper(token)
+	if upper == "UTC" || upper == "GMT" || upper == "Z" {
+		return time.UTC, true
+	}
+	if m := offsetPattern.FindStringSubmatch(token); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes, _ := strconv.Atoi(m[3])
+		offset := hours*60*60 + minutes*60
+		if m[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(token, offset), true
+	}
+	// The abbreviations of the location in winter and in summer.
+	for _, month := range []time.Month{time.January, time.July} {
+		t := time.Date(now.Year(), month, 1, 12, 0, 0, 0, p.location())
+		if name, _ := t.Zone(); name == upper {
+			return p.location(), true
+		}
+	}
+	return nil, false
+}
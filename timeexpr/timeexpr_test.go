@@ -0,0 +1,134 @@
+package timeexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("EDT", -4*60*60)
+	// Wednesday
+	now := time.Date(2021, 3, 31, 14, 20, 30, 0, loc)
+	p := Parser{Now: func() time.Time { return now }, Location: loc}
+	at := func(month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(2021, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"now", now},
+		{"  NOW ", now},
+		{"-15m", now.Add(-15 * time.Minute)},
+		{"now-1h", now.Add(-time.Hour)},
+		{"now - 1h30m", now.Add(-90 * time.Minute)},
+		{"+30m", now.Add(30 * time.Minute)},
+		{"15:30", at(3, 31, 15, 30)},
+		{"9:05:07", at(3, 31, 9, 5).Add(7 * time.Second)},
+		{"3pm", at(3, 31, 15, 0)},
+		{"3:04PM", at(3, 31, 15, 4)},
+		{"3:04 pm", at(3, 31, 15, 4)},
+		{"12am", at(3, 31, 0, 0)},
+		{"12:30pm", at(3, 31, 12, 30)},
+		{"noon", at(3, 31, 12, 0)},
+		{"today", at(3, 31, 0, 0)},
+		{"yesterday 3pm", at(3, 30, 15, 0)},
+		{"tomorrow 9:00", at(4, 1, 9, 0)},
+		{"mon 9:30", at(3, 29, 9, 30)},
+		{"Monday", at(3, 29, 0, 0)},
+		{"wed", at(3, 31, 0, 0)},
+		{"last wed", at(3, 24, 0, 0)},
+		{"last friday", at(3, 26, 0, 0)},
+		{"last fri 5pm", at(3, 26, 17, 0)},
+		{"thu", at(3, 25, 0, 0)},
+		{"2021-03-30", at(3, 30, 0, 0)},
+		{"2021-03-30 15:04", at(3, 30, 15, 4)},
+		{"2021-03-30T15:04", at(3, 30, 15, 4)},
+		{"2021-03-30T15:04:05-04:00", at(3, 30, 15, 4).Add(5 * time.Second)},
+		{"2021-03-30T19:04:05Z", at(3, 30, 15, 4).Add(5 * time.Second)},
+		{"2021-03-30T15:04+02:00", at(3, 30, 9, 4)},
+		{"2021-03-30 9:00 AM EDT", at(3, 30, 9, 0)},
+		{"2021-03-30 1:00 PM UTC", at(3, 30, 9, 0)},
+		{"2021-03-30 13:00 +0000", at(3, 30, 9, 0)},
+		{"03-30", at(3, 30, 0, 0)},
+		{"03-30 3:04:05 PM", at(3, 30, 15, 4).Add(5 * time.Second)},
+		{"3/30 9:00", at(3, 30, 9, 0)},
+		{"3/30/2020", time.Date(2020, 3, 30, 0, 0, 0, 0, loc)},
+		{"mar 30", at(3, 30, 0, 0)},
+		{"March 30 2020 10am", time.Date(2020, 3, 30, 10, 0, 0, 0, loc)},
+		{"dec 31", at(12, 31, 0, 0)},
+	}
+	for _, test := range tests {
+		got, err := p.Parse(test.expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.expr, err)
+			continue
+		}
+		if !got.Equal(test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.expr, test.expected, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	p := Parser{Now: func() time.Time { return time.Date(2021, 3, 31, 14, 20, 0, 0, time.UTC) }, Location: time.UTC}
+	for _, expr := range []string{
+		"",
+		"soon",
+		"3",
+		"25:00",
+		"13pm",
+		"-15x",
+		"last",
+		"last week",
+		"mar",
+		"feb 30",
+		"02-29",
+		"mon tue",
+		"3pm 4pm",
+		"9:00 PST",
+		"EDT",
+	} {
+		if got, err := p.Parse(expr); err == nil {
+			t.Errorf("%q: expected an error, got %v", expr, got)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	now := time.Date(2021, 3, 31, 14, 20, 0, 0, time.UTC)
+	p := Parser{
+		Now:           func() time.Time { return now },
+		Location:      time.UTC,
+		ParseDuration: func(s string) (time.Duration, error) { return 24 * time.Hour, nil },
+	}
+	got, err := p.Parse("-1d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(now.AddDate(0, 0, -1)) {
+		t.Errorf("Expected the custom duration parser to be used, got %v", got)
+	}
+}
+
+func TestParseRelativeSpans(t *testing.T) {
+	now := time.Date(2021, 3, 31, 14, 20, 0, 0, time.UTC)
+	p := Parser{Now: func() time.Time { return now }, Location: time.UTC}
+	tests := map[string]time.Time{
+		"-1d":      now.AddDate(0, 0, -1),
+		"now-1w":   now.AddDate(0, 0, -7),
+		"-1mo":     time.Date(2021, 2, 28, 14, 20, 0, 0, time.UTC),
+		"now+1:30": now.Add(90 * time.Minute),
+	}
+	for expr, expected := range tests {
+		got, err := p.Parse(expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", expr, err)
+			continue
+		}
+		if !got.Equal(expected) {
+			t.Errorf("%q: expected %v, got %v", expr, expected, got)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package track
+
+import (
+	"fmt"
+	"time"
+)
+
+// Break is an interval inside an entry that is not worked. A break that
+// has not finished yet means that the entry is paused.
+type Break struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+}
+
+// Duration returns the length of the break. A break that has not
+// finished lasts until until.
+func (b Break) Duration(until time.Time) time.Duration {
+	finishedAt := b.FinishedAt
+	if finishedAt.IsZero() {
+		finishedAt = until
+	}
+	if finishedAt.Before(b.StartedAt) {
+		return 0
+	}
+	return finishedAt.Sub(b.StartedAt)
+}
+
+// StringIn returns a string representation of the Break object with
+// times in loc.
+func (b Break) StringIn(loc *time.Location) string {
+	f := "now"
+	if !b.FinishedAt.IsZero() {
+		f = b.FinishedAt.In(loc).Format("15:04")
+	}
+	return fmt.Sprintf("%s-%s", b.StartedAt.In(loc).Format("15:04"), f)
+}
+
+// Paused returns true if the entry is on a break.
+func (entry *Entry) Paused() bool {
+	return len(entry.Breaks) > 0 && entry.Breaks[len(entry.Breaks)-1].FinishedAt.IsZero()
+}
+
+// BreakTime returns the total length of the entry's breaks. A break that
+// has not finished lasts until the entry finishes or until now if the
+// entry is in progress.
+func (entry *Entry) BreakTime() time.Duration {
+	return entry.breakTimeAt(time.Now().UTC())
+}
+
+// breakTimeAt returns the total length of the entry's breaks with now as
+// the current time.
+func (entry *Entry) breakTimeAt(now time.Time) time.Duration {
+	until := endAt(*entry, now)
+	var total time.Duration
+	for _, b := range entry.Breaks {
+		total += b.Duration(until)
+	}
+	return total
+}
+
+// Pause starts a break at at. Only entries in progress that are not
+// paused can be paused.
+func (entry *Entry) Pause(at time.Time) error {
+	if !entry.InProgress() {
+		return fmt.Errorf("only entries in progress can be paused")
+	}
+	if entry.Paused() {
+		return fmt.Errorf("the entry has been paused since %s", entry.Breaks[len(entry.Breaks)-1].StartedAt.In(at.Location()).Format(time.Kitchen))
+	}
+	if at.Before(entry.StartedAt) {
+		return fmt.Errorf("a break must not start before the entry (%v)", entry.StartedAt)
+	}
+	if len(entry.Breaks) > 0 {
+		if last := entry.Breaks[len(entry.Breaks)-1]; at.Before(last.FinishedAt) {
+			return fmt.Errorf("a break must not start before the last break finished (%v)", last.FinishedAt)
+		}
+	}
+	entry.Breaks = append(entry.Breaks, Break{StartedAt: at})
+	return nil
+}
+
+// Unpause finishes the current break at at.
+func (entry *Entry) Unpause(at time.Time) error {
+	if !entry.Paused() {
+		return fmt.Errorf("the entry is not paused")
+	}
+	last := &entry.Breaks[len(entry.Breaks)-1]
+	if at.Before(last.StartedAt) {
+		return fmt.Errorf("a break must not finish before it started (%v)", last.StartedAt)
+	}
+	last.FinishedAt = at
+	return nil
+}
+
+// finishAfter finishes the entry once worked time has passed outside of
+// its breaks. Breaks that start at or after the finish time are removed.
+// The breaks must be finished.
+func (entry *Entry) finishAfter(worked time.Duration) {
+	finishedAt := entry.StartedAt
+	remaining := worked
+	breaks := []Break{}
+	for _, b := range entry.Breaks {
+		available := b.StartedAt.Sub(finishedAt)
+		if remaining <= available {
+			break
+		}
+		remaining -= available
+		breaks = append(breaks, b)
+		finishedAt = b.FinishedAt
+	}
+	if len(breaks) == 0 {
+		breaks = nil
+	}
+	entry.Breaks = breaks
+	entry.FinishedAt = finishedAt.Add(remaining)
+	entry.Duration = int(worked.Seconds())
+}
+
+// breakProblems lists the breaks that are outside of the entry or overlap
+// each other.
+func breakProblems(entry Entry) []string {
+	res := []string{}
+	previous := entry.StartedAt
+	for ix, b := range entry.Breaks {
+		switch {
+		case b.StartedAt.Before(previous):
+			res = append(res, fmt.Sprintf("break %d starts before the entry or the break before it", ix+1))
+		case !b.FinishedAt.IsZero() && b.FinishedAt.Before(b.StartedAt):
+			res = append(res, fmt.Sprintf("break %d finishes before it starts", ix+1))
+		case !entry.InProgress() && (b.FinishedAt.IsZero() || b.FinishedAt.After(entry.FinishedAt)):
+			res = append(res, fmt.Sprintf("break %d finishes after the entry", ix+1))
+		case b.FinishedAt.IsZero() && ix < len(entry.Breaks)-1:
+			res = append(res, fmt.Sprintf("break %d has no finish time", ix+1))
+		}
+		previous = b.FinishedAt
+	}
+	return res
+}
@@ -0,0 +1,151 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaks(t *testing.T) {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	entry := Entry{StartedAt: started}
+
+	if err := entry.Unpause(started.Add(time.Hour)); err == nil {
+		t.Error("Expected an error when unpausing an entry that is not paused")
+	}
+	if err := entry.Pause(started.Add(-time.Minute)); err == nil {
+		t.Error("Expected an error for a break before the entry")
+	}
+	if err := entry.Pause(started.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if !entry.Paused() {
+		t.Error("Expected the entry to be paused")
+	}
+	if err := entry.Pause(started.Add(2 * time.Hour)); err == nil {
+		t.Error("Expected an error when pausing a paused entry")
+	}
+	if err := entry.Unpause(started.Add(75 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := entry.Pause(started.Add(70 * time.Minute)); err == nil {
+		t.Error("Expected an error for a break that starts during the last break")
+	}
+	if err := entry.Pause(started.Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Finishing a paused entry finishes the break.
+	if err := entry.End(time.Hour, time.Time{}); err == nil {
+		t.Error("Expected an error when setting the duration of a paused entry")
+	}
+	if err := entry.End(0, started.Add(150*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Paused() || entry.BreakTime() != 45*time.Minute {
+		t.Errorf("Expected 45m of finished breaks, got %v", entry.Breaks)
+	}
+	if entry.Duration != 105*60 || Elapsed(entry) != 105*time.Minute {
+		t.Errorf("Expected a duration of 1h45m, got %v", Elapsed(entry))
+	}
+	if problems := problems(entry); len(problems) > 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+
+	// Setting the duration moves the finish time past the breaks.
+	if err := entry.End(2*time.Hour, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if !entry.FinishedAt.Equal(started.Add(165 * time.Minute)) {
+		t.Errorf("Expected the entry to finish at 11:45, got %v", entry.FinishedAt)
+	}
+	if err := entry.End(0, started.Add(100*time.Minute)); err == nil {
+		t.Error("Expected an error when finishing before the last break finished")
+	}
+
+	entry.Breaks[1].FinishedAt = started.Add(3 * time.Hour)
+	if problems := problems(entry); len(problems) != 1 {
+		t.Errorf("Expected a problem with the break after the entry, got %v", problems)
+	}
+}
+
+func TestElapsedPaused(t *testing.T) {
+	started := time.Now().UTC().Add(-time.Hour)
+	entry := Entry{StartedAt: started}
+	if err := entry.Pause(started.Add(40 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := Elapsed(entry).Round(time.Minute); elapsed != 40*time.Minute {
+		t.Errorf("Expected 40m without the break in progress, got %v", elapsed)
+	}
+}
+
+func TestKeepLocalBreaks(t *testing.T) {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	local := Entry{ID: 1, ExternalID: 2, StartedAt: started, Breaks: []Break{{StartedAt: started.Add(time.Hour), FinishedAt: started.Add(90 * time.Minute)}}}
+	if err := local.End(0, started.Add(3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	remote := Entry{ExternalID: 2, StartedAt: started, FinishedAt: started.Add(150 * time.Minute), Duration: local.Duration}
+
+	merged := keepLocalFields(remote, local)
+	if !merged.FinishedAt.Equal(local.FinishedAt) || len(merged.Breaks) != 1 {
+		t.Errorf("Expected the local finish time and breaks, got %v", merged)
+	}
+
+	remote.Duration = 3600
+	if merged := keepLocalFields(remote, local); len(merged.Breaks) != 0 {
+		t.Errorf("Expected the breaks to be dropped when the duration changed, got %v", merged.Breaks)
+	}
+
+	if fields := Diff(local, merged); len(fields) != 0 {
+		t.Errorf("Expected no differences, got %v", fields)
+	}
+	changed := local
+	changed.Breaks = nil
+	if fields := Diff(local, changed); len(fields) != 1 || fields[0].Field != "breaks" {
+		t.Errorf("Expected the breaks to differ, got %v", fields)
+	}
+}
+
+func TestRoundEntryWithBreaks(t *testing.T) {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	newEntry := func() Entry {
+		entry := Entry{StartedAt: started, Breaks: []Break{{StartedAt: started.Add(40 * time.Minute), FinishedAt: started.Add(50 * time.Minute)}}}
+		if err := entry.End(0, started.Add(55*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		return entry
+	}
+
+	tests := []struct {
+		name     string
+		rounding Rounding
+		duration time.Duration
+	}{
+		{"down", Rounding{Mode: RoundDown, Increment: 30 * time.Minute}, 30 * time.Minute},
+		{"down to zero", Rounding{Mode: RoundDown, Increment: time.Hour}, 0},
+		{"up", Rounding{Mode: RoundUp, Increment: time.Hour}, time.Hour},
+	}
+	for _, test := range tests {
+		entry := newEntry()
+		if err := test.rounding.RoundEntry(&entry); err != nil {
+			t.Errorf("(%s) unexpected error: %v", test.name, err)
+			continue
+		}
+		if !entry.FinishedAt.Equal(started.Add(55*time.Minute)) || Elapsed(entry) != test.duration || len(entry.Breaks) != 1 {
+			t.Errorf("(%s) unexpected entry: finished at %v after %v with breaks %v", test.name, entry.FinishedAt, Elapsed(entry), entry.Breaks)
+		}
+		if problems := problems(entry); len(problems) > 0 {
+			t.Errorf("(%s) expected no problems, got %v", test.name, problems)
+		}
+	}
+
+	// A shorter duration removes the break after the new finish time.
+	entry := newEntry()
+	if err := entry.End(35*time.Minute, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if !entry.FinishedAt.Equal(started.Add(35*time.Minute)) || len(entry.Breaks) != 0 {
+		t.Errorf("Expected the entry to finish at 9:35 without breaks, got %v and %v", entry.FinishedAt, entry.Breaks)
+	}
+}
@@ -10,11 +10,23 @@ type Client struct {
 	Nickname  string
 	ClientID  int
 	ProjectID int
+	// Name, Address and Email are optional details that are shown on
+	// rendered timesheets and invoices.
+	Name    string
+	Address string
+	Email   string
 }
 
 // String returns a string representation of the Client object.
 func (client *Client) String() string {
-	return fmt.Sprintf("Nickname: %s\nClient ID: %d\nProject ID: %d\n", client.Nickname, client.ClientID, client.ProjectID)
+	res := fmt.Sprintf("Nickname: %s\nClient ID: %d\nProject ID: %d\n", client.Nickname, client.ClientID, client.ProjectID)
+	if client.Name != "" {
+		res += fmt.Sprintf("Name: %s\n", client.Name)
+	}
+	if client.Email != "" {
+		res += fmt.Sprintf("Email: %s\n", client.Email)
+	}
+	return res
 }
 
 // AddClient adds a new client to a list of clients.
@@ -54,3 +66,20 @@ func FilterClients(clients []Client, params Client) []Client {
 	}
 	return res
 }
+
+// ClientFor finds the client for an entry. Clients that match both the
+// client and project ID are preferred over clients that only match the
+// client ID.
+func ClientFor(clients []Client, entry Entry) (Client, bool) {
+	for _, client := range clients {
+		if client.ClientID == entry.ClientID && client.ProjectID == entry.ProjectID {
+			return client, true
+		}
+	}
+	for _, client := range clients {
+		if client.ClientID == entry.ClientID {
+			return client, true
+		}
+	}
+	return Client{}, false
+}
@@ -3,7 +3,9 @@ package track
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,25 @@ type Entry struct {
 	ClientID    int       `json:"client_id"`
 	ProjectID   int       `json:"project_id,omitempty"`
 	ExternalID  int       `json:"external_id,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Billable    bool      `json:"billable"`
+	InvoiceID   int       `json:"invoice_id,omitempty"`
+	Breaks      []Break   `json:"breaks,omitempty"`
+	Timer       string    `json:"timer,omitempty"`
+	DeletedAt   time.Time `json:"deleted_at,omitempty"`
+	History     []Change  `json:"history,omitempty"`
+}
+
+// UnmarshalJSON reads an Entry from JSON. Entries are billable unless
+// they say otherwise.
+func (entry *Entry) UnmarshalJSON(data []byte) error {
+	type entryJSON Entry
+	res := entryJSON{Billable: true}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	*entry = Entry(res)
+	return nil
 }
 
 // GetDuration converts Duration into a time.Duration object.
@@ -24,16 +45,66 @@ func (entry *Entry) GetDuration() (time.Duration, error) {
 	return time.ParseDuration(fmt.Sprintf("%d", entry.Duration) + "s")
 }
 
-// End derives the duration and finished at times.
-func (entry *Entry) End(duration time.Duration, finishedAt time.Time) {
+// End derives the duration and finished at times. The duration is the
+// time worked, which excludes breaks. If the duration is set, the entry
+// finishes once that much time has been worked between its breaks, and
+// breaks after the finish time are removed. An entry that is paused is
+// unpaused when it finishes. An error is returned if the duration is
+// negative or the entry would finish before it started or during a break.
+func (entry *Entry) End(duration time.Duration, finishedAt time.Time) error {
+	if duration < 0 {
+		return fmt.Errorf("duration must not be negative, got %v", duration)
+	}
 	if duration.Seconds() > 0 {
-		entry.FinishedAt = entry.StartedAt.Add(duration)
-		entry.Duration = int(duration.Seconds())
+		if entry.Paused() {
+			return fmt.Errorf("the entry is paused, unpause it or set the finish time instead of the duration")
+		}
+		entry.finishAfter(duration)
 	} else if !finishedAt.IsZero() {
+		if finishedAt.Before(entry.StartedAt) {
+			return fmt.Errorf("finished at (%v) must not be before started at (%v)", finishedAt, entry.StartedAt)
+		}
+		if entry.Paused() {
+			if err := entry.Unpause(finishedAt); err != nil {
+				return err
+			}
+		}
+		if len(entry.Breaks) > 0 {
+			if last := entry.Breaks[len(entry.Breaks)-1]; finishedAt.Before(last.FinishedAt) {
+				return fmt.Errorf("finished at (%v) must not be before the last break finished (%v)", finishedAt, last.FinishedAt)
+			}
+		}
 		entry.FinishedAt = finishedAt
-		duration := entry.FinishedAt.Sub(entry.StartedAt)
+		duration := entry.FinishedAt.Sub(entry.StartedAt) - entry.BreakTime()
 		entry.Duration = int(duration.Seconds())
 	}
+	return nil
+}
+
+// HasTag returns true if the entry is tagged with tag. Tags are not case
+// sensitive.
+func (entry *Entry) HasTag(tag string) bool {
+	for _, t := range entry.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags adds tags that the entry does not have yet.
+func (entry *Entry) AddTags(tags ...string) {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !entry.HasTag(tag) {
+			entry.Tags = append(entry.Tags, tag)
+		}
+	}
+}
+
+// IsDeleted returns true if the entry is a tombstone for a deleted entry.
+func (entry *Entry) IsDeleted() bool {
+	return !entry.DeletedAt.IsZero()
 }
 
 // InProgress returns true if the entry has not been completed.
@@ -41,6 +112,16 @@ func (entry *Entry) InProgress() bool {
 	return entry.FinishedAt == time.Time{}
 }
 
+// IsZero returns true if the entry has not been set.
+func (entry *Entry) IsZero() bool {
+	return entry.ID == 0 && entry.ExternalID == 0 && entry.StartedAt.IsZero()
+}
+
+// Equal returns true if all fields except for the history are the same.
+func (entry *Entry) Equal(other Entry) bool {
+	return len(Diff(*entry, other)) == 0
+}
+
 // JSON returns Entry as JSON object with indent.
 func (entry *Entry) JSON() ([]byte, error) {
 	return json.MarshalIndent(entry, "", "  ")
@@ -48,10 +129,16 @@ func (entry *Entry) JSON() ([]byte, error) {
 
 // String returns a string representation of the Entry object.
 func (entry *Entry) String() string {
-	s := entry.StartedAt.Local().Format(time.UnixDate)
+	return entry.StringIn(time.Local)
+}
+
+// StringIn returns a string representation of the Entry object with
+// times in loc.
+func (entry *Entry) StringIn(loc *time.Location) string {
+	s := entry.StartedAt.In(loc).Format(time.UnixDate)
 	f := "In progress"
 	if !entry.FinishedAt.IsZero() {
-		f = entry.FinishedAt.Local().Format(time.UnixDate)
+		f = entry.FinishedAt.In(loc).Format(time.UnixDate)
 	}
 	var id string
 	if entry.ExternalID > 0 {
@@ -60,7 +147,20 @@ func (entry *Entry) String() string {
 		id = fmt.Sprintf("ID: %v", entry.ID)
 	}
 	d, _ := entry.GetDuration()
-	return fmt.Sprintf("Description: %s\nStarted At: %s\nFinished At: %s\nDuration: %v\n%s\nClient ID: %d", entry.Description, s, f, d.Round(time.Minute), id, entry.ClientID)
+	res := fmt.Sprintf("Description: %s\nStarted At: %s\nFinished At: %s\nDuration: %v\n%s\nClient ID: %d", entry.Description, s, f, d.Round(time.Minute), id, entry.ClientID)
+	if len(entry.Tags) > 0 {
+		res += fmt.Sprintf("\nTags: %s", strings.Join(entry.Tags, ", "))
+	}
+	if !entry.Billable {
+		res += "\nNot billable"
+	}
+	if entry.InvoiceID > 0 {
+		res += fmt.Sprintf("\nInvoice ID: %d", entry.InvoiceID)
+	}
+	if entry.Timer != "" {
+		res += fmt.Sprintf("\nTimer: %s", entry.Timer)
+	}
+	return res
 }
 
 // MostRecentEntry returns the most recent entry if the entries slice
@@ -75,6 +175,22 @@ func MostRecentEntry(entries []Entry) Entry {
 	return recent
 }
 
+// FindEntry looks up an entry by its ID or, if no entry has that ID, by
+// its external ID.
+func FindEntry(entries []Entry, id int) (Entry, bool) {
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	for _, entry := range entries {
+		if entry.ExternalID != 0 && entry.ExternalID == id {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
 // NextID determines the next ID from a list of entries.
 func NextID(entries []Entry) int {
 	nextID := 0
@@ -86,15 +202,27 @@ func NextID(entries []Entry) int {
 	return nextID + 1
 }
 
-// FilterParameters declares the parameters for FilterEntries.
+// FilterParameters declares the parameters for FilterEntries. Zero
+// values are ignored and all of the other parameters must match.
 type FilterParameters struct {
-	Since       time.Time
-	Until       time.Time
-	Description string
+	Since time.Time
+	Until time.Time
+	// Description matches descriptions containing this text.
+	Description   string
+	CaseSensitive bool
+	// Pattern matches descriptions with this regular expression.
+	Pattern     *regexp.Regexp
+	ClientID    int
+	ProjectID   int
+	Tags        []string
+	Billable    *bool
+	MinDuration time.Duration
+	MaxDuration time.Duration
 	Limit       int
 }
 
-// FilterEntries searches by start, end, and description.
+// FilterEntries searches by start, end, description, client, project,
+// tags, billable, and duration.
 func FilterEntries(entries []Entry, params FilterParameters) []Entry {
 	res := make([]Entry, len(entries))
 	copy(res, entries)
@@ -118,20 +246,98 @@ func FilterEntries(entries []Entry, params FilterParameters) []Entry {
 		}
 	}
 
-	// if (params.Description != "") {
-	// 	TODO
-	// }
+	filtered := []Entry{}
+	for _, entry := range res {
+		if params.matches(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	res = filtered
+
 	if len(res) > params.Limit && params.Limit > 0 {
 		res = res[len(res)-params.Limit:]
 	}
 	return res
 }
 
+// matches checks all parameters except for the time range and limit.
+func (params *FilterParameters) matches(entry Entry) bool {
+	if params.Description != "" {
+		description, substr := entry.Description, params.Description
+		if !params.CaseSensitive {
+			description, substr = strings.ToLower(description), strings.ToLower(substr)
+		}
+		if !strings.Contains(description, substr) {
+			return false
+		}
+	}
+	if params.Pattern != nil && !params.Pattern.MatchString(entry.Description) {
+		return false
+	}
+	if params.ClientID > 0 && entry.ClientID != params.ClientID {
+		return false
+	}
+	if params.ProjectID > 0 && entry.ProjectID != params.ProjectID {
+		return false
+	}
+	for _, tag := range params.Tags {
+		if !entry.HasTag(tag) {
+			return false
+		}
+	}
+	if params.Billable != nil && entry.Billable != *params.Billable {
+		return false
+	}
+	if params.MinDuration > 0 || params.MaxDuration > 0 {
+		duration := Elapsed(entry)
+		if params.MinDuration > 0 && duration < params.MinDuration {
+			return false
+		}
+		if params.MaxDuration > 0 && duration > params.MaxDuration {
+			return false
+		}
+	}
+	return true
+}
+
+// Elapsed returns the duration of a finished entry or the time since an
+// entry in progress was started, excluding breaks.
+func Elapsed(entry Entry) time.Duration {
+	return elapsedAt(entry, time.Now().UTC())
+}
+
+// elapsedAt returns the duration of an entry with now as the current time.
+func elapsedAt(entry Entry, now time.Time) time.Duration {
+	if entry.InProgress() {
+		return endAt(entry, now).Sub(entry.StartedAt) - entry.breakTimeAt(now)
+	}
+	return time.Duration(entry.Duration) * time.Second
+}
+
 // SortEntries using the StartedAt attribute.
 func SortEntries(entries []Entry) {
 	sort.SliceStable(entries, func(i int, j int) bool { return entries[i].StartedAt.Before(entries[j].StartedAt) })
 }
 
+// keepLocalFields copies the fields that are only stored locally to an
+// entry from FreshBooks.
+func keepLocalFields(remote Entry, local Entry) Entry {
+	if len(local.Tags) > 0 {
+		remote.Tags = local.Tags
+	}
+	remote.InvoiceID = local.InvoiceID
+	remote.Timer = local.Timer
+	if len(local.Breaks) > 0 && remote.Duration == local.Duration {
+		// FreshBooks only stores the duration, so the finish time is
+		// kept from the breaks unless the duration has changed.
+		remote.FinishedAt = local.FinishedAt
+		remote.Breaks = local.Breaks
+	}
+	remote.DeletedAt = local.DeletedAt
+	remote.History = local.History
+	return remote
+}
+
 // UpdateEntries merges entries in left with entries in right or add
 // new entries.
 func UpdateEntries(left []Entry, right []Entry, on string) ([]Entry, error) {
@@ -170,8 +376,12 @@ func UpdateEntries(left []Entry, right []Entry, on string) ([]Entry, error) {
 		}
 
 		if val, exists := index[key]; exists {
-			// ensure ID isn't lost if matching on ExternalID
+			// ensure ID and local only fields aren't lost if matching on
+			// ExternalID
 			entry.ID = val.Entry.ID
+			if on == "ExternalID" {
+				entry = keepLocalFields(entry, val.Entry)
+			}
 			result[val.Index] = entry
 		} else {
 			newEntries = append(newEntries, entry)
@@ -1,7 +1,9 @@
 package track
 
 import (
+	"encoding/json"
 	"log"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -106,3 +108,66 @@ func TestUpdateEntries(t *testing.T) {
 		t.Errorf("Description is: %s", result[2].Description)
 	}
 }
+
+func TestEntriesAreBillableByDefault(t *testing.T) {
+	var entries []Entry
+	data := []byte(`[{"id": 1, "description": "Old entry"}, {"id": 2, "billable": false}]`)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	if !entries[0].Billable {
+		t.Errorf("Expected entry without billable field to be billable")
+	}
+	if entries[1].Billable {
+		t.Errorf("Expected entry to not be billable")
+	}
+}
+
+func TestFilterEntriesByTag(t *testing.T) {
+	entries := mockEntries()
+	entries[0].AddTags("meeting", "review")
+	entries[1].AddTags("Meeting")
+	entries[1].Billable = true
+
+	if res := FilterEntries(entries, FilterParameters{Tags: []string{"meeting"}}); len(res) != 2 {
+		t.Errorf("Expected 2 entries tagged meeting, got %v", len(res))
+	}
+	if res := FilterEntries(entries, FilterParameters{Tags: []string{"meeting", "review"}}); len(res) != 1 {
+		t.Errorf("Expected 1 entry tagged meeting and review, got %v", len(res))
+	}
+	billable := true
+	if res := FilterEntries(entries, FilterParameters{Billable: &billable}); len(res) != 1 || res[0].ExternalID != 456 {
+		t.Errorf("Expected 1 billable entry, got %v", res)
+	}
+}
+
+func TestFilterEntriesByDescriptionClientAndDuration(t *testing.T) {
+	entries := mockEntries()
+	entries[0].ClientID = 1
+	entries[0].ProjectID = 10
+	entries[1].ClientID = 1
+	entries[1].ProjectID = 20
+	for ix, minutes := range []int{120, 30, 45, 45} {
+		entries[ix].Duration = minutes * 60
+	}
+
+	tests := []struct {
+		name     string
+		params   FilterParameters
+		expected int
+	}{
+		{"substring", FilterParameters{Description: "write SOME"}, 3},
+		{"case sensitive", FilterParameters{Description: "write SOME", CaseSensitive: true}, 0},
+		{"regex", FilterParameters{Pattern: regexp.MustCompile(`(code|tests)$`)}, 2},
+		{"client", FilterParameters{ClientID: 1}, 2},
+		{"client and project", FilterParameters{ClientID: 1, ProjectID: 20}, 1},
+		{"min duration", FilterParameters{MinDuration: 45 * time.Minute}, 3},
+		{"max duration", FilterParameters{MaxDuration: 30 * time.Minute}, 1},
+		{"combined", FilterParameters{Description: "write", ClientID: 1, MinDuration: time.Hour}, 1},
+	}
+	for _, test := range tests {
+		if res := FilterEntries(entries, test.params); len(res) != test.expected {
+			t.Errorf("(%s) Expected %d entries, got %d", test.name, test.expected, len(res))
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hdoupe/ttrack/oauth"
@@ -23,6 +24,8 @@ type TimeEntry struct {
 	ID        int       `json:"id"`
 	IsLogged  bool      `json:"is_logged"`
 	Active    bool      `json:"active"`
+	Billable  *bool     `json:"billable,omitempty"`
+	ServiceID int       `json:"service_id,omitempty"`
 }
 
 // TimeEntryPayload is the data structure for data posted to freshbooks.com.
@@ -48,15 +51,28 @@ func (timeEntry *TimeEntry) ToEntry() Entry {
 		ClientID:    timeEntry.ClientID,
 		ProjectID:   timeEntry.ProjectID,
 		ExternalID:  timeEntry.ID,
+		Billable:    timeEntry.Billable == nil || *timeEntry.Billable,
 	}
 }
 
-func (entry *Entry) toTimeEntry() TimeEntry {
+// toTimeEntry converts an Entry to a TimeEntry. The service is set from
+// the first tag that is in services, which maps tags to service IDs.
+func (entry *Entry) toTimeEntry(services map[string]int) TimeEntry {
 	duration, err := entry.GetDuration()
 	if err != nil {
 		log.Fatal(err)
 	}
+	billable := entry.Billable
+	serviceID := 0
+	for _, tag := range entry.Tags {
+		if id, ok := services[strings.ToLower(tag)]; ok {
+			serviceID = id
+			break
+		}
+	}
 	return TimeEntry{
+		Billable:  &billable,
+		ServiceID: serviceID,
 		Active:    true,
 		StartedAt: entry.StartedAt,
 		Duration:  int(duration.Seconds()),
@@ -72,86 +88,180 @@ func (entry *Entry) toTimeEntry() TimeEntry {
 type FreshBooks struct {
 	LogLocation string
 	Credentials oauth.Credentials
+	// Command is recorded in the history of entries that are saved.
+	Command string
+	// Force saves entries even if they are not valid.
+	Force bool
+	// Services maps lower case tags to FreshBooks service IDs.
+	Services map[string]int
+	// Rounding rounds entries when they are finished if it applies at
+	// finish time. Clients are used to find the rounding for entries.
+	Rounding RoundingPolicy
+	Clients  []Client
+	// Location is used to show times in messages. Defaults to
+	// time.Local.
+	Location *time.Location
+}
+
+// local returns the tracker for the local copy of the FreshBooks entries.
+func (tracker *FreshBooks) local() Local {
+	return Local{LogLocation: tracker.LogLocation, Command: tracker.Command, Force: tracker.Force, Location: tracker.Location}
+}
+
+func (tracker *FreshBooks) location() *time.Location {
+	if tracker.Location == nil {
+		return time.Local
+	}
+	return tracker.Location
 }
 
 // Start entry on FreshBooks.
 func (tracker *FreshBooks) Start(entry Entry) Entry {
 	entries := tracker.LoadEntries()
 
-	recent := MostRecentEntry(entries)
-	if (recent != Entry{} && recent.InProgress()) {
-		log.Fatal(fmt.Sprintf("The last item in the log is missing a finish time:\n %v", recent))
+	if running, ok := runningConflict(entries, entry); ok {
+		log.Fatal(fmt.Sprintf("An entry is already in progress for this timer:\n %v", running.StringIn(tracker.location())))
 	}
+	checkEntries([]Entry{entry}, entries, tracker.Force)
 
 	entry = tracker.CreateEntry(entry)
 
-	local := Local{LogLocation: tracker.LogLocation}
-	local.SaveEntries([]Entry{entry})
-	return entry
+	local := tracker.local()
+	return local.SaveEntries([]Entry{entry})[0]
 }
 
-// Finish entry on FreshBooks.
+// Finish the entry in progress for the timer of entry on FreshBooks. The
+// tags of entry are added to the entry in progress and it is marked as not
+// billable if entry is not billable.
 func (tracker *FreshBooks) Finish(entry Entry) Entry {
 	entries := tracker.LoadEntries()
 	if len(entries) == 0 {
 		log.Fatal("There are no entries to update.")
 	}
 
-	recent := MostRecentEntry(entries)
-
-	if (recent.FinishedAt != time.Time{}) {
-		data, err := json.MarshalIndent(recent, "", "  ")
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.Fatal("This would overwrite the most recent entry", string(data))
+	recent, err := RunningEntry(entries, entry.Timer)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	if entry.Description != "" {
 		recent.Description = entry.Description
 	}
+	recent.AddTags(entry.Tags...)
+	recent.Billable = recent.Billable && entry.Billable
 
 	duration, err := entry.GetDuration()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	recent.End(duration, entry.FinishedAt)
+	if err := recent.End(duration, entry.FinishedAt); err != nil {
+		log.Fatal(err)
+	}
+	if rounding := tracker.Rounding.For(tracker.Clients, recent); rounding.Apply == ApplyFinish {
+		if err := rounding.RoundEntry(&recent); err != nil {
+			log.Fatal(err)
+		}
+	}
+	checkEntries([]Entry{recent}, entries, tracker.Force)
 
 	recent = tracker.UpdateEntry(recent)
-	local := Local{LogLocation: tracker.LogLocation}
-	local.SaveEntries([]Entry{recent})
+	local := tracker.local()
+	return local.SaveEntries([]Entry{recent})[0]
+}
 
-	return recent
+// Switch finishes the entry in progress for the timer on FreshBooks and
+// starts entry. Both entries are checked before either is sent and each
+// is saved to the local log once it has been sent. It returns the
+// finished and the started entry.
+func (tracker *FreshBooks) Switch(timer string, entry Entry) (Entry, Entry) {
+	entries := tracker.LoadEntries()
+	finished, started := switchEntries(entries, timer, entry, tracker.Rounding, tracker.Clients, tracker.location())
+	checkEntries([]Entry{finished, started}, entries, tracker.Force)
+
+	// The finished entry is saved right away so that the local copy
+	// matches FreshBooks even if the new entry cannot be created.
+	local := tracker.local()
+	finished = local.SaveEntries([]Entry{tracker.UpdateEntry(finished)})[0]
+	started = local.SaveEntries([]Entry{tracker.CreateEntry(started)})[0]
+	return finished, started
 }
 
 // LoadEntries loads all entries from freshbooks. The entries are synced
 // with the local entries using their ExternalID.
 func (tracker *FreshBooks) LoadEntries() []Entry {
+	entries, _, _ := tracker.loadEntries()
+	return entries
+}
+
+// loadEntries merges the entries from freshbooks with the local entries.
+// It also returns the local entries that no longer exist on freshbooks
+// and the entries on freshbooks that have been deleted locally. Local
+// entries are only treated as removed if every page of entries was
+// retrieved from freshbooks.
+func (tracker *FreshBooks) loadEntries() ([]Entry, []Entry, []Entry) {
 	businessID := RetrieveBusinessID(tracker.Credentials)
-	timeEntries := RetrieveTimeEntries(businessID, tracker.Credentials)
+	timeEntries, complete := RetrieveTimeEntries(businessID, tracker.Credentials)
 
+	serviceTags := map[int]string{}
+	for tag, id := range tracker.Services {
+		serviceTags[id] = tag
+	}
+
+	remote := map[int]bool{}
 	entries := []Entry{}
 	for _, timeEntry := range timeEntries {
-		entries = append(entries, timeEntry.ToEntry())
+		entry := timeEntry.ToEntry()
+		if tag, ok := serviceTags[timeEntry.ServiceID]; ok {
+			entry.AddTags(tag)
+		}
+		entries = append(entries, entry)
+		remote[timeEntry.ID] = true
 	}
 
-	local := Local{LogLocation: tracker.LogLocation}
-	locEntries := local.LoadEntries()
+	local := tracker.local()
+	locEntries := local.loadAll()
 
 	entries, err := UpdateEntries(locEntries, entries, "ExternalID")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	SortEntries(entries)
+	return splitRemote(entries, remote, complete)
+}
 
-	return entries
+// splitRemote splits merged entries into the entries to keep, the local
+// entries that no longer exist on freshbooks and the entries that have
+// been deleted locally but still exist on freshbooks. remote holds the
+// external IDs that were retrieved. If the retrieved entries are not
+// complete, no entries are treated as removed.
+func splitRemote(entries []Entry, remote map[int]bool, complete bool) ([]Entry, []Entry, []Entry) {
+	res := []Entry{}
+	removed := []Entry{}
+	deleted := []Entry{}
+	for _, entry := range entries {
+		switch {
+		case entry.IsDeleted():
+			if remote[entry.ExternalID] {
+				deleted = append(deleted, entry)
+			}
+		case complete && entry.ExternalID > 0 && !remote[entry.ExternalID]:
+			removed = append(removed, entry)
+		default:
+			res = append(res, entry)
+		}
+	}
+
+	SortEntries(res)
+
+	return res, removed, deleted
 }
 
-// SaveEntries creates new entries and updates existing entries to FreshBooks.
+// SaveEntries creates new entries and updates existing entries to
+// FreshBooks. The local copy of the entries is updated too.
 func (tracker *FreshBooks) SaveEntries(entries []Entry) []Entry {
 	currEntries := tracker.LoadEntries()
+	checkEntries(entries, currEntries, tracker.Force)
 
 	res := []Entry{}
 	for _, entry := range entries {
@@ -159,14 +269,15 @@ func (tracker *FreshBooks) SaveEntries(entries []Entry) []Entry {
 			res = append(res, tracker.CreateEntry(entry))
 		} else {
 			for _, curr := range currEntries {
-				if curr.ExternalID == entry.ExternalID && curr != entry {
+				if curr.ExternalID == entry.ExternalID && !curr.Equal(entry) {
 					res = append(res, tracker.UpdateEntry(entry))
 				}
 			}
 		}
 	}
 
-	return res
+	local := tracker.local()
+	return local.SaveEntries(res)
 }
 
 // CreateEntry saves just one Entry to FreshBooks.
@@ -174,7 +285,7 @@ func (tracker *FreshBooks) CreateEntry(entry Entry) Entry {
 	businessID := RetrieveBusinessID(tracker.Credentials)
 	url := fmt.Sprintf("https://api.freshbooks.com/timetracking/business/%s/time_entries", fmt.Sprint(businessID))
 
-	timeEntry := TimeEntryPayload{TimeEntry: entry.toTimeEntry()}
+	timeEntry := TimeEntryPayload{TimeEntry: entry.toTimeEntry(tracker.Services)}
 	payload, err := json.Marshal(timeEntry)
 	if err != nil {
 		log.Fatal(err)
@@ -230,7 +341,7 @@ func (tracker *FreshBooks) UpdateEntry(entry Entry) Entry {
 		fmt.Sprint(entry.ExternalID),
 	)
 
-	timeEntry := TimeEntryPayload{TimeEntry: entry.toTimeEntry()}
+	timeEntry := TimeEntryPayload{TimeEntry: entry.toTimeEntry(tracker.Services)}
 	payload, err := json.Marshal(timeEntry)
 	if err != nil {
 		log.Fatal(err)
@@ -257,30 +368,136 @@ func (tracker *FreshBooks) UpdateEntry(entry Entry) Entry {
 	return entry
 }
 
-// SyncEntries on FreshBooks with a local file.
+// Delete removes entries from FreshBooks and marks them as deleted in
+// the local file. Entries that have not been saved locally yet are only
+// deleted on FreshBooks.
+func (tracker *FreshBooks) Delete(entries []Entry) []Entry {
+	saved := []Entry{}
+	remote := []Entry{}
+	for _, entry := range entries {
+		if entry.ID == 0 && entry.ExternalID == 0 {
+			log.Fatal("Unable to delete entry because it has not been saved: \n", entry.StringIn(tracker.location()))
+		}
+		if entry.ID == 0 {
+			remote = append(remote, entry)
+		} else {
+			saved = append(saved, entry)
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.ExternalID > 0 {
+			tracker.DeleteEntry(entry)
+		}
+	}
+	now := time.Now().UTC()
+	for ix := range remote {
+		remote[ix].DeletedAt = now
+	}
+	local := tracker.local()
+	if len(saved) == 0 {
+		return remote
+	}
+	return append(local.Delete(saved), remote...)
+}
+
+// DeleteEntry deletes an entry on freshbooks.com
+func (tracker *FreshBooks) DeleteEntry(entry Entry) {
+	if entry.ExternalID == 0 {
+		log.Fatal("Unable to delete entry because the external id is not defined.", entry)
+	}
+	businessID := RetrieveBusinessID(tracker.Credentials)
+	url := fmt.Sprintf(
+		"https://api.freshbooks.com/timetracking/business/%s/time_entries/%s",
+		fmt.Sprint(businessID),
+		fmt.Sprint(entry.ExternalID),
+	)
+
+	req, err := http.NewRequest("DELETE", url, bytes.NewReader([]byte{}))
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Add("Authorization", "Bearer "+tracker.Credentials.AccessToken)
+	req.Header.Add("API-Version", "alpha")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 204 && resp.StatusCode != 404 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		log.Fatal("Unexpected error when deleting time entry (", resp.StatusCode, ")", string(body[:]))
+	}
+}
+
+// SyncEntries on FreshBooks with a local file. Entries that were deleted
+// locally are deleted on FreshBooks and entries that were deleted on
+// FreshBooks are deleted locally if every page of entries was retrieved.
 func (tracker *FreshBooks) SyncEntries() {
-	entries := tracker.LoadEntries()
-	local := Local{LogLocation: tracker.LogLocation}
+	entries, removed, deleted := tracker.loadEntries()
+	for _, entry := range deleted {
+		tracker.DeleteEntry(entry)
+	}
+	local := tracker.local()
+	local.Origin = OriginFreshBooks
+	// Entries on FreshBooks are saved as they are.
+	local.Force = true
 	local.SaveEntries(entries)
+	if len(removed) > 0 {
+		local.Delete(removed)
+	}
+}
+
+// timeEntriesPerPage is the number of time entries requested per page.
+const timeEntriesPerPage = 100
+
+// PageMeta describes a page of results from FreshBooks.
+type PageMeta struct {
+	Page    int `json:"page"`
+	Pages   int `json:"pages"`
+	PerPage int `json:"per_page"`
+	Total   int `json:"total"`
+}
+
+// RetrieveTimeEntries returns a list of time entries from FreshBooks. It
+// also returns whether every page was retrieved, so that entries missing
+// from the list can be trusted to have been deleted on FreshBooks.
+func RetrieveTimeEntries(businessID int, credentials oauth.Credentials) ([]TimeEntry, bool) {
+	return collectPages(func(page int) ([]TimeEntry, PageMeta) {
+		return RetrieveTimeEntriesPage(businessID, credentials, page)
+	})
 }
 
-// RetrieveTimeEntries returns a list of time entries from FreshBooks.
-func RetrieveTimeEntries(businessID int, credentials oauth.Credentials) []TimeEntry {
-	var result []TimeEntry
-	timeEntries, hasMore := RetrieveTimeEntriesPage(businessID, credentials, 0)
-	result = append(result, timeEntries...)
-	page := 1
-	for hasMore {
-		timeEntries, hasMore = RetrieveTimeEntriesPage(businessID, credentials, page)
+// collectPages fetches pages starting at page 1 until the last page. The
+// result is complete if every page was the one requested and the number of
+// entries matches the total reported by the last page.
+func collectPages(fetch func(page int) ([]TimeEntry, PageMeta)) ([]TimeEntry, bool) {
+	result := []TimeEntry{}
+	complete := true
+	for page := 1; ; page++ {
+		timeEntries, meta := fetch(page)
 		result = append(result, timeEntries...)
-		page++
+		if meta.Page != page {
+			complete = false
+		}
+		if page >= meta.Pages {
+			return result, complete && len(result) == meta.Total
+		}
 	}
-	return result
 }
 
 // RetrieveTimeEntriesPage returns a page of time entries from FreshBooks.
-func RetrieveTimeEntriesPage(businessID int, credentials oauth.Credentials, page int) ([]TimeEntry, bool) {
-	url := fmt.Sprintf("https://api.freshbooks.com/timetracking/business/%s/time_entries", fmt.Sprint(businessID))
+// Pages start at 1.
+func RetrieveTimeEntriesPage(businessID int, credentials oauth.Credentials, page int) ([]TimeEntry, PageMeta) {
+	url := fmt.Sprintf(
+		"https://api.freshbooks.com/timetracking/business/%s/time_entries?page=%d&per_page=%d",
+		fmt.Sprint(businessID),
+		page,
+		timeEntriesPerPage,
+	)
 	req, err := http.NewRequest("GET", url, bytes.NewReader([]byte{}))
 	if err != nil {
 		log.Fatal(err)
@@ -306,15 +523,12 @@ func RetrieveTimeEntriesPage(businessID int, credentials oauth.Credentials, page
 	}
 	var timeEntries struct {
 		TimeEntries []TimeEntry `json:"time_entries"`
-		Meta        struct {
-			Pages int `json:"pages"`
-			Page  int `json:"page"`
-		}
+		Meta        PageMeta    `json:"meta"`
 	}
 	if err := json.Unmarshal(body, &timeEntries); err != nil {
 		log.Fatal(err)
 	}
-	return timeEntries.TimeEntries, timeEntries.Meta.Page < timeEntries.Meta.Pages
+	return timeEntries.TimeEntries, timeEntries.Meta
 }
 
 // Me is the data from the Me response that is necessary to use ttrack.
@@ -323,7 +537,8 @@ type Me struct {
 		ID                  int `json:"id"`
 		BusinessMemberships []struct {
 			Business struct {
-				ID int `json:"id"`
+				ID        int    `json:"id"`
+				AccountID string `json:"account_id"`
 			} `json:"business"`
 		} `json:"business_memberships"`
 	} `json:"response"`
@@ -332,6 +547,18 @@ type Me struct {
 // RetrieveBusinessID gets the user's business ID to be used for the
 // time tracking API calls.
 func RetrieveBusinessID(credentials oauth.Credentials) int {
+	return retrieveMe(credentials).Response.BusinessMemberships[0].Business.ID
+}
+
+// RetrieveAccountID gets the user's account ID to be used for the
+// accounting API calls.
+func RetrieveAccountID(credentials oauth.Credentials) string {
+	return retrieveMe(credentials).Response.BusinessMemberships[0].Business.AccountID
+}
+
+// retrieveMe gets the user's identity. It has exactly one business
+// membership.
+func retrieveMe(credentials oauth.Credentials) Me {
 	url := "https://api.freshbooks.com/auth/api/v1/users/me"
 
 	req, err := http.NewRequest("GET", url, bytes.NewReader([]byte{}))
@@ -369,5 +596,5 @@ func RetrieveBusinessID(credentials oauth.Credentials) int {
 		log.Fatal("TODO: Let user select business membership.")
 	}
 
-	return data.Response.BusinessMemberships[0].Business.ID
+	return data
 }
@@ -0,0 +1,59 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCollectPages(t *testing.T) {
+	pages := [][]TimeEntry{{{ID: 1}, {ID: 2}}, {{ID: 3}}}
+	fetch := func(total int, skip int) func(page int) ([]TimeEntry, PageMeta) {
+		return func(page int) ([]TimeEntry, PageMeta) {
+			if page == skip {
+				// FreshBooks returned the first page again.
+				return pages[0], PageMeta{Page: 1, Pages: len(pages), Total: total}
+			}
+			return pages[page-1], PageMeta{Page: page, Pages: len(pages), Total: total}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		fetch    func(page int) ([]TimeEntry, PageMeta)
+		count    int
+		complete bool
+	}{
+		{"all pages", fetch(3, 0), 3, true},
+		{"missing entries", fetch(4, 0), 3, false},
+		{"wrong page", fetch(3, 2), 4, false},
+		{"no entries", func(page int) ([]TimeEntry, PageMeta) { return nil, PageMeta{Page: 1} }, 0, true},
+		{"no meta", func(page int) ([]TimeEntry, PageMeta) { return pages[0], PageMeta{} }, 2, false},
+	}
+	for _, test := range tests {
+		entries, complete := collectPages(test.fetch)
+		if len(entries) != test.count || complete != test.complete {
+			t.Errorf("(%s) expected %d entries and complete %v, got %d and %v", test.name, test.count, test.complete, len(entries), complete)
+		}
+	}
+}
+
+func TestSplitRemote(t *testing.T) {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{ID: 1, ExternalID: 10, StartedAt: started},
+		{ID: 2, ExternalID: 20, StartedAt: started.Add(time.Hour)},
+		{ID: 3, ExternalID: 30, StartedAt: started.Add(2 * time.Hour), DeletedAt: started},
+		{ID: 4, StartedAt: started.Add(3 * time.Hour)},
+	}
+	remote := map[int]bool{10: true, 30: true}
+
+	res, removed, deleted := splitRemote(entries, remote, true)
+	if len(res) != 2 || len(removed) != 1 || removed[0].ID != 2 || len(deleted) != 1 || deleted[0].ID != 3 {
+		t.Errorf("Unexpected split of a complete fetch: %v, %v, %v", res, removed, deleted)
+	}
+
+	res, removed, deleted = splitRemote(entries, remote, false)
+	if len(res) != 3 || len(removed) != 0 || len(deleted) != 1 {
+		t.Errorf("Expected no entries to be removed after a partial fetch, got %v, %v, %v", res, removed, deleted)
+	}
+}
@@ -0,0 +1,176 @@
+package track
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ClockRange is a period of the day given as offsets from midnight.
+type ClockRange struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// WorkHours maps days of the week to the periods of those days that are
+// worked.
+type WorkHours map[time.Weekday][]ClockRange
+
+// DefaultWorkHours is 9:00 to 17:00, Monday through Friday.
+func DefaultWorkHours() WorkHours {
+	hours := WorkHours{}
+	for day := time.Monday; day <= time.Friday; day++ {
+		hours[day] = []ClockRange{{Start: 9 * time.Hour, End: 17 * time.Hour}}
+	}
+	return hours
+}
+
+// ParseWorkHours reads work hours from a map of lower case day names to
+// comma separated HH:MM-HH:MM ranges, e.g. "09:00-12:00,13:00-17:00".
+// Days that are not in the map are not worked.
+func ParseWorkHours(days map[string]string) (WorkHours, error) {
+	hours := WorkHours{}
+	for name, value := range days {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q in work hours", name)
+		}
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			bounds := strings.SplitN(part, "-", 2)
+			if len(bounds) != 2 {
+				return nil, fmt.Errorf("work hours for %s must look like 09:00-17:00, got %q", name, part)
+			}
+			start, startErr := parseClock(bounds[0])
+			finish, finishErr := parseClock(bounds[1])
+			if startErr != nil || finishErr != nil || finish <= start {
+				return nil, fmt.Errorf("work hours for %s must look like 09:00-17:00, got %q", name, part)
+			}
+			hours[day] = append(hours[day], ClockRange{Start: start, End: finish})
+		}
+	}
+	return hours, nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// parseClock converts HH:MM into an offset from midnight.
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		if strings.TrimSpace(value) == "24:00" {
+			return 24 * time.Hour, nil
+		}
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Gap is a period of working time without any entries. Before is the
+// entry that finishes when the gap starts and After is the entry that
+// starts when the gap finishes, if there are any.
+type Gap struct {
+	Start  time.Time
+	End    time.Time
+	Before Entry
+	After  Entry
+}
+
+// Duration returns the length of the gap.
+func (gap *Gap) Duration() time.Duration {
+	return gap.End.Sub(gap.Start)
+}
+
+// StringIn returns a string representation of the Gap object with times
+// in loc.
+func (gap *Gap) StringIn(loc *time.Location) string {
+	start := gap.Start.In(loc)
+	return fmt.Sprintf(
+		"%s %s - %s (%v)",
+		start.Format("Mon Jan _2"),
+		start.Format("15:04"),
+		gap.End.In(loc).Format("15:04"),
+		gap.Duration().Round(time.Minute),
+	)
+}
+
+// Gaps finds the periods of working time between since and until that
+// are not covered by any entries. Days are determined in loc. Gaps
+// shorter than minimum are skipped.
+func Gaps(entries []Entry, hours WorkHours, since time.Time, until time.Time, loc *time.Location, minimum time.Duration) []Gap {
+	sorted := []Entry{}
+	for _, entry := range entries {
+		if !entry.IsDeleted() {
+			sorted = append(sorted, entry)
+		}
+	}
+	SortEntries(sorted)
+
+	res := []Gap{}
+	first := since.In(loc)
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(until); day = day.AddDate(0, 0, 1) {
+		for _, period := range hours[day.Weekday()] {
+			start := time.Date(day.Year(), day.Month(), day.Day(), 0, int(period.Start.Minutes()), 0, 0, loc)
+			finish := time.Date(day.Year(), day.Month(), day.Day(), 0, int(period.End.Minutes()), 0, 0, loc)
+			if start.Before(since) {
+				start = since
+			}
+			if finish.After(until) {
+				finish = until
+			}
+			if !start.Before(finish) {
+				continue
+			}
+			res = append(res, findGaps(sorted, start, finish, minimum)...)
+		}
+	}
+	return res
+}
+
+// findGaps subtracts the sorted entries from the period between start
+// and finish.
+func findGaps(sorted []Entry, start time.Time, finish time.Time, minimum time.Duration) []Gap {
+	res := []Gap{}
+	cursor := start
+	var before Entry
+	for _, entry := range sorted {
+		entryEnd := end(entry)
+		if !entryEnd.After(cursor) {
+			if entryEnd.Equal(cursor) {
+				before = entry
+			}
+			continue
+		}
+		if !entry.StartedAt.Before(finish) {
+			break
+		}
+		if entry.StartedAt.After(cursor) {
+			gap := Gap{Start: cursor, End: entry.StartedAt, Before: before, After: entry}
+			if gap.Duration() >= minimum {
+				res = append(res, gap)
+			}
+		}
+		cursor = entryEnd
+		before = entry
+	}
+	if cursor.Before(finish) {
+		gap := Gap{Start: cursor, End: finish, Before: before}
+		if gap.Duration() >= minimum {
+			res = append(res, gap)
+		}
+	}
+	return res
+}
@@ -0,0 +1,51 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWorkHours(t *testing.T) {
+	hours, err := ParseWorkHours(map[string]string{"monday": "09:00-12:00, 13:00-17:00", "sat": "10:00-12:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hours[time.Monday]) != 2 || hours[time.Saturday][0].End != 12*time.Hour {
+		t.Errorf("Unexpected work hours: %v", hours)
+	}
+	for _, bad := range []map[string]string{{"someday": "09:00-17:00"}, {"monday": "17:00-09:00"}, {"monday": "9-5"}} {
+		if _, err := ParseWorkHours(bad); err == nil {
+			t.Errorf("Expected error for %v", bad)
+		}
+	}
+}
+
+func TestGaps(t *testing.T) {
+	entries := mockEntries()
+	for ix, minutes := range []int{120, 30, 45, 15} {
+		entries[ix].FinishedAt = entries[ix].StartedAt.Add(time.Duration(minutes) * time.Minute)
+		entries[ix].Duration = minutes * 60
+	}
+	hours := WorkHours{time.Saturday: []ClockRange{{Start: 9 * time.Hour, End: 18 * time.Hour}}}
+	since := time.Date(2020, 11, 21, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 0, 2)
+
+	gaps := Gaps(entries, hours, since, until, time.UTC, time.Minute)
+	// 9:00-10:00, 12:00-13:00, 13:30-15:00, 15:45-17:00, 17:15-18:00
+	if len(gaps) != 5 {
+		t.Fatalf("Expected 5 gaps, got %v", gaps)
+	}
+	if !gaps[0].Before.IsZero() || gaps[0].After.ExternalID != 123 || gaps[0].Duration() != time.Hour {
+		t.Errorf("Unexpected first gap: %v", gaps[0])
+	}
+	if gaps[1].Before.ExternalID != 123 || gaps[1].After.ExternalID != 456 {
+		t.Errorf("Unexpected second gap: %v", gaps[1])
+	}
+	if gaps[4].Before.ExternalID != 257 || !gaps[4].After.IsZero() || gaps[4].Duration() != 45*time.Minute {
+		t.Errorf("Unexpected last gap: %v", gaps[4])
+	}
+
+	if gaps := Gaps(entries, hours, since, until, time.UTC, time.Hour); len(gaps) != 4 {
+		t.Errorf("Expected 4 gaps of at least an hour, got %v", gaps)
+	}
+}
@@ -0,0 +1,92 @@
+package track
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Origins of a change to an entry.
+const (
+	OriginLocal      = "local"
+	OriginFreshBooks = "freshbooks"
+	OriginImport     = "import"
+)
+
+// Actions recorded in an entry's history.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// FieldChange describes the old and new value of one entry field.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Change is one item in the audit history of an entry.
+type Change struct {
+	At      time.Time     `json:"at"`
+	Action  string        `json:"action"`
+	Command string        `json:"command,omitempty"`
+	Origin  string        `json:"origin"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+// StringIn returns a string representation of the Change object with
+// times in loc.
+func (change *Change) StringIn(loc *time.Location) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s", change.At.In(loc).Format(time.UnixDate), change.Action)
+	if change.Command != "" {
+		fmt.Fprintf(&b, " by '%s'", change.Command)
+	}
+	fmt.Fprintf(&b, " (%s)", change.Origin)
+	for _, field := range change.Fields {
+		fmt.Fprintf(&b, "\n    %s: %q -> %q", field.Field, field.Old, field.New)
+	}
+	return b.String()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatBreaks(breaks []Break) string {
+	res := make([]string, len(breaks))
+	for ix, b := range breaks {
+		res[ix] = formatTime(b.StartedAt) + "/" + formatTime(b.FinishedAt)
+	}
+	return strings.Join(res, ",")
+}
+
+// Diff lists the fields that differ between the old and new entry. The
+// entry's history is not compared.
+func Diff(old Entry, new Entry) []FieldChange {
+	changes := []FieldChange{}
+	add := func(field string, o string, n string) {
+		if o != n {
+			changes = append(changes, FieldChange{Field: field, Old: o, New: n})
+		}
+	}
+	add("description", old.Description, new.Description)
+	add("started_at", formatTime(old.StartedAt), formatTime(new.StartedAt))
+	add("finished_at", formatTime(old.FinishedAt), formatTime(new.FinishedAt))
+	add("duration", fmt.Sprint(old.Duration), fmt.Sprint(new.Duration))
+	add("client_id", fmt.Sprint(old.ClientID), fmt.Sprint(new.ClientID))
+	add("project_id", fmt.Sprint(old.ProjectID), fmt.Sprint(new.ProjectID))
+	add("external_id", fmt.Sprint(old.ExternalID), fmt.Sprint(new.ExternalID))
+	add("tags", strings.Join(old.Tags, ","), strings.Join(new.Tags, ","))
+	add("billable", fmt.Sprint(old.Billable), fmt.Sprint(new.Billable))
+	add("invoice_id", fmt.Sprint(old.InvoiceID), fmt.Sprint(new.InvoiceID))
+	add("breaks", formatBreaks(old.Breaks), formatBreaks(new.Breaks))
+	add("timer", old.Timer, new.Timer)
+	add("deleted_at", formatTime(old.DeletedAt), formatTime(new.DeletedAt))
+	return changes
+}
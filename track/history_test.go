@@ -0,0 +1,58 @@
+package track
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveEntriesRecordsHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ttrack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tracker := Local{LogLocation: filepath.Join(dir, "log.json"), Command: "ttrack test"}
+	entry := mockEntries()[0]
+	entry.ID = 0
+	tracker.SaveEntries([]Entry{entry})
+
+	entries := tracker.LoadEntries()
+	if len(entries[0].History) != 1 || entries[0].History[0].Action != ActionCreate {
+		t.Fatalf("Expected one create change, got %v", entries[0].History)
+	}
+
+	entry = entries[0]
+	entry.Description = "Write some more code"
+	tracker.SaveEntries([]Entry{entry})
+	tracker.SaveEntries([]Entry{entry})
+
+	history := tracker.LoadEntries()[0].History
+	if len(history) != 2 {
+		t.Fatalf("Expected two changes, got %v", history)
+	}
+	change := history[1]
+	if change.Action != ActionUpdate || change.Command != "ttrack test" || change.Origin != OriginLocal {
+		t.Errorf("Unexpected change: %v", change)
+	}
+	if len(change.Fields) != 1 || change.Fields[0].Field != "description" || change.Fields[0].New != "Write some more code" {
+		t.Errorf("Unexpected field changes: %v", change.Fields)
+	}
+}
+
+func TestImportRecordsOrigin(t *testing.T) {
+	tracker, cleanup := tempLocal(t)
+	defer cleanup()
+
+	entry := mockEntries()[0]
+	entry.ID = 0
+	saved := tracker.Import([]Entry{entry})
+	if history := saved[0].History; len(history) != 1 || history[0].Origin != OriginImport {
+		t.Errorf("Expected the entry to be created by an import, got %v", history)
+	}
+	if tracker.Origin != "" {
+		t.Errorf("Expected the tracker origin to be restored, got %q", tracker.Origin)
+	}
+}
@@ -0,0 +1,269 @@
+package track
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Line item groupings.
+const (
+	LineByDay         = "day"
+	LineByTag         = "tag"
+	LineByDescription = "description"
+)
+
+// LineItemGroupings lists the ways entries can be grouped into line items.
+var LineItemGroupings = []string{LineByDay, LineByTag, LineByDescription}
+
+// LineItem is one line of an invoice. Hours are rounded to hundredths of
+// an hour like they are on FreshBooks.
+type LineItem struct {
+	Name        string
+	Description string
+	Hours       float64
+	Rate        Rate
+	Entries     []Entry
+}
+
+// Amount returns the amount of the line item.
+func (item *LineItem) Amount() float64 {
+	return math.Round(item.Hours*item.Rate.Hourly*100) / 100
+}
+
+// Invoice is a draft invoice for a client.
+type Invoice struct {
+	ClientID   int
+	CreateDate time.Time
+	Currency   string
+	Lines      []LineItem
+}
+
+// Total returns the sum of the line item amounts.
+func (invoice *Invoice) Total() float64 {
+	var res float64
+	for _, item := range invoice.Lines {
+		res += item.Amount()
+	}
+	return math.Round(res*100) / 100
+}
+
+// Entries returns the entries of all line items.
+func (invoice *Invoice) Entries() []Entry {
+	res := []Entry{}
+	for _, item := range invoice.Lines {
+		res = append(res, item.Entries...)
+	}
+	return res
+}
+
+// InvoiceOptions configures how entries are turned into an invoice.
+type InvoiceOptions struct {
+	ClientID int
+	// By is one of LineItemGroupings.
+	By       string
+	Rates    []Rate
+	Clients  []Client
+	Rounding RoundingPolicy
+	Location *time.Location
+}
+
+// lineKey returns the name of the line item for an entry.
+func lineKey(entry Entry, by string, loc *time.Location) string {
+	switch by {
+	case LineByTag:
+		if len(entry.Tags) == 0 {
+			return "Other"
+		}
+		return strings.ToLower(entry.Tags[0])
+	case LineByDescription:
+		if entry.Description == "" {
+			return "Other"
+		}
+		return entry.Description
+	default:
+		return entry.StartedAt.In(loc).Format("2006-01-02")
+	}
+}
+
+// unique joins the distinct values in order.
+func unique(values []string, sep string) string {
+	seen := map[string]bool{}
+	res := []string{}
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			res = append(res, value)
+		}
+	}
+	return strings.Join(res, sep)
+}
+
+// NewInvoice groups the billable entries that have not been invoiced
+// into line items. Entries are grouped by day, by their first tag, or by
+// description, and entries with different rates are put on separate
+// lines. Durations are rounded with the rounding policy. An error is
+// returned if an entry does not have a rate or if the rates use more
+// than one currency.
+func NewInvoice(entries []Entry, opts InvoiceOptions) (Invoice, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	switch opts.By {
+	case "":
+		opts.By = LineByDay
+	case LineByDay, LineByTag, LineByDescription:
+	default:
+		return Invoice{}, fmt.Errorf("unknown line item grouping %q, use one of %s", opts.By, strings.Join(LineItemGroupings, ", "))
+	}
+
+	invoice := Invoice{ClientID: opts.ClientID, CreateDate: time.Now().In(opts.Location)}
+	index := map[string]int{}
+	durations := []time.Duration{}
+	for _, entry := range entries {
+		if !entry.Billable || entry.InvoiceID > 0 || entry.InProgress() {
+			continue
+		}
+		rate, ok := RateFor(opts.Rates, opts.Clients, entry)
+		if !ok {
+			return Invoice{}, fmt.Errorf("no rate for entry:\n%s", entry.StringIn(opts.Location))
+		}
+		if invoice.Currency == "" {
+			invoice.Currency = rate.Currency
+		} else if rate.Currency != invoice.Currency {
+			return Invoice{}, fmt.Errorf("entries use more than one currency: %s and %s", invoice.Currency, rate.Currency)
+		}
+
+		name := lineKey(entry, opts.By, opts.Location)
+		key := fmt.Sprintf("%s\x00%v", name, rate.Hourly)
+		ix, exists := index[key]
+		if !exists {
+			ix = len(invoice.Lines)
+			index[key] = ix
+			invoice.Lines = append(invoice.Lines, LineItem{Name: name, Rate: rate})
+			durations = append(durations, 0)
+		}
+		invoice.Lines[ix].Entries = append(invoice.Lines[ix].Entries, entry)
+		durations[ix] += opts.Rounding.Rounded(opts.Clients, entry)
+	}
+
+	for ix := range invoice.Lines {
+		item := &invoice.Lines[ix]
+		item.Hours = math.Round(durations[ix].Hours()*100) / 100
+		details := []string{}
+		for _, entry := range item.Entries {
+			if opts.By == LineByDescription {
+				details = append(details, entry.StartedAt.In(opts.Location).Format("Jan 2"))
+			} else {
+				details = append(details, entry.Description)
+			}
+		}
+		item.Description = unique(details, "; ")
+	}
+	if opts.By == LineByDay {
+		sort.SliceStable(invoice.Lines, func(i int, j int) bool { return invoice.Lines[i].Name < invoice.Lines[j].Name })
+	}
+	return invoice, nil
+}
+
+// invoiceLine is a FreshBooks invoice line.
+type invoiceLine struct {
+	Type        int    `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Qty         string `json:"qty"`
+	UnitCost    struct {
+		Amount string `json:"amount"`
+		Code   string `json:"code"`
+	} `json:"unit_cost"`
+}
+
+// invoicePayload is the data structure for invoices posted to
+// freshbooks.com.
+type invoicePayload struct {
+	Invoice struct {
+		CustomerID   int           `json:"customerid"`
+		CreateDate   string        `json:"create_date"`
+		CurrencyCode string        `json:"currency_code,omitempty"`
+		Status       int           `json:"status"`
+		Lines        []invoiceLine `json:"lines"`
+	} `json:"invoice"`
+}
+
+// CreateInvoice creates a draft invoice on FreshBooks and returns its ID.
+func (tracker *FreshBooks) CreateInvoice(invoice Invoice) int {
+	accountID := RetrieveAccountID(tracker.Credentials)
+	url := fmt.Sprintf("https://api.freshbooks.com/accounting/account/%s/invoices/invoices", accountID)
+
+	var data invoicePayload
+	data.Invoice.CustomerID = invoice.ClientID
+	data.Invoice.CreateDate = invoice.CreateDate.Format("2006-01-02")
+	data.Invoice.CurrencyCode = invoice.Currency
+	// Draft
+	data.Invoice.Status = 1
+	for _, item := range invoice.Lines {
+		line := invoiceLine{Name: item.Name, Description: item.Description, Qty: fmt.Sprintf("%.2f", item.Hours)}
+		line.UnitCost.Amount = fmt.Sprintf("%.2f", item.Rate.Hourly)
+		line.UnitCost.Code = item.Rate.Currency
+		data.Invoice.Lines = append(data.Invoice.Lines, line)
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Add("Authorization", "Bearer "+tracker.Credentials.AccessToken)
+	req.Header.Add("API-Version", "alpha")
+	req.Header.Add("Content-Type", "application/json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if resp.StatusCode != 200 {
+		log.Fatal("Unexpected error when creating invoice (", resp.StatusCode, ")", string(body[:]))
+	}
+
+	var res struct {
+		Response struct {
+			Result struct {
+				Invoice struct {
+					ID int `json:"id"`
+				} `json:"invoice"`
+			} `json:"result"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil || res.Response.Result.Invoice.ID == 0 {
+		log.Fatal("Unable to parse response from FreshBooks: ", string(body[:]))
+	}
+	return res.Response.Result.Invoice.ID
+}
+
+// MarkInvoiced records the invoice ID on the entries in the local file so
+// that they are not invoiced again. The entries are saved even if they
+// are not valid because the invoice already exists on FreshBooks.
+func (tracker *FreshBooks) MarkInvoiced(entries []Entry, invoiceID int) []Entry {
+	for ix := range entries {
+		entries[ix].InvoiceID = invoiceID
+	}
+	local := tracker.local()
+	local.Force = true
+	return local.SaveEntries(entries)
+}
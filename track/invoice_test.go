@@ -0,0 +1,83 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewInvoice(t *testing.T) {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	entry := func(id int, start time.Time, d time.Duration, description string, tags ...string) Entry {
+		return Entry{
+			ID:          id,
+			StartedAt:   start,
+			FinishedAt:  start.Add(d),
+			Duration:    int(d.Seconds()),
+			Description: description,
+			ClientID:    1,
+			Tags:        tags,
+			Billable:    true,
+		}
+	}
+	entries := []Entry{
+		entry(1, started.AddDate(0, 0, 1), time.Hour, "Deploy", "ops"),
+		entry(2, started, 50*time.Minute, "Write code", "dev"),
+		entry(3, started.Add(2*time.Hour), time.Hour, "Review", "review"),
+		entry(4, started.Add(4*time.Hour), time.Hour, "Write code", "dev"),
+		entry(5, started.Add(6*time.Hour), time.Hour, "Lunch"),
+		entry(6, started.Add(8*time.Hour), time.Hour, "Invoiced"),
+	}
+	entries[4].Billable = false
+	entries[5].InvoiceID = 99
+
+	opts := InvoiceOptions{
+		ClientID: 1,
+		Clients:  []Client{{Nickname: "acme", ClientID: 1}},
+		Rates:    []Rate{{Client: "acme", Hourly: 100, Currency: "USD"}, {Tag: "review", Hourly: 150, Currency: "USD"}},
+		Rounding: RoundingPolicy{Default: Rounding{Mode: RoundUp, Increment: 15 * time.Minute}},
+		Location: time.UTC,
+	}
+	invoice, err := NewInvoice(entries, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoice.Lines) != 3 {
+		t.Fatalf("Expected 3 line items, got %v", invoice.Lines)
+	}
+	first := invoice.Lines[0]
+	if first.Name != "2021-03-30" || first.Hours != 2 || first.Description != "Write code" || len(first.Entries) != 2 {
+		t.Errorf("Unexpected first line item: %+v", first)
+	}
+	if invoice.Lines[1].Rate.Hourly != 150 || invoice.Lines[2].Name != "2021-03-31" {
+		t.Errorf("Expected entries with different rates on separate lines, got %+v", invoice.Lines)
+	}
+	if invoice.Total() != 450 || invoice.Currency != "USD" {
+		t.Errorf("Expected a total of 450 USD, got %v %s", invoice.Total(), invoice.Currency)
+	}
+
+	opts.By = LineByTag
+	invoice, err = NewInvoice(entries, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoice.Lines) != 3 || invoice.Lines[0].Name != "ops" {
+		t.Errorf("Expected line items by tag, got %+v", invoice.Lines)
+	}
+
+	opts.Rates = opts.Rates[1:]
+	if _, err := NewInvoice(entries, opts); err == nil {
+		t.Error("Expected an error for entries without a rate.")
+	}
+}
+
+func TestKeepInvoiceID(t *testing.T) {
+	local := []Entry{{ID: 1, ExternalID: 10, InvoiceID: 99}}
+	remote := []Entry{{ExternalID: 10, Description: "Updated"}}
+	merged, err := UpdateEntries(local, remote, "ExternalID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged[0].InvoiceID != 99 {
+		t.Errorf("Expected the invoice ID to be kept, got %v", merged[0])
+	}
+}
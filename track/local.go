@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 )
@@ -14,51 +15,137 @@ import (
 // Local file system time tracker.
 type Local struct {
 	LogLocation string
+	// Command is recorded in the history of entries that are saved.
+	Command string
+	// Origin is recorded in the history of entries that are saved.
+	// Defaults to OriginLocal.
+	Origin string
+	// Force saves entries even if they are not valid.
+	Force bool
+	// Rounding rounds entries when they are finished if it applies at
+	// finish time. Clients are used to find the rounding for entries.
+	Rounding RoundingPolicy
+	Clients  []Client
+	// Location is used to show times in messages. Defaults to
+	// time.Local.
+	Location *time.Location
+}
+
+func (tracker *Local) location() *time.Location {
+	if tracker.Location == nil {
+		return time.Local
+	}
+	return tracker.Location
 }
 
 // Start adds a new entry to the log.
 func (tracker *Local) Start(entry Entry) Entry {
 	entries := tracker.LoadEntries()
-	recent := MostRecentEntry(entries)
-	if (recent != Entry{} && recent.InProgress()) {
-		log.Fatal(fmt.Sprintf("The last item in the log is missing a finish time:\n %v", recent))
+	if running, ok := runningConflict(entries, entry); ok {
+		log.Fatal(fmt.Sprintf("An entry is already in progress for this timer:\n %v", running.StringIn(tracker.location())))
 	}
 
-	tracker.SaveEntries([]Entry{entry})
-	return entry
+	return tracker.SaveEntries([]Entry{entry})[0]
 }
 
-// Finish adds an end time to the most recent entry in the log.
+// Finish adds an end time to the entry in progress for the timer of
+// entry. The tags of entry are added to the entry in progress and it is
+// marked as not billable if entry is not billable.
 func (tracker *Local) Finish(entry Entry) Entry {
 	entries := tracker.LoadEntries()
 	if len(entries) == 0 {
 		log.Fatal("There are no entries to update.")
 	}
 
-	recent := MostRecentEntry(entries)
-	if !recent.FinishedAt.IsZero() {
-		log.Fatal("This would overwrite the most recent entry: \n", recent)
+	recent, err := RunningEntry(entries, entry.Timer)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	if entry.Description != "" {
 		recent.Description = entry.Description
 	}
+	recent.AddTags(entry.Tags...)
+	recent.Billable = recent.Billable && entry.Billable
 
 	duration, err := entry.GetDuration()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	recent.End(duration, entry.FinishedAt)
+	if err := recent.End(duration, entry.FinishedAt); err != nil {
+		log.Fatal(err)
+	}
+	if rounding := tracker.Rounding.For(tracker.Clients, recent); rounding.Apply == ApplyFinish {
+		if err := rounding.RoundEntry(&recent); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	fmt.Println("Updated entry in log at position: ", len(entries)-1)
 
-	tracker.SaveEntries([]Entry{recent})
-	return entry
+	return tracker.SaveEntries([]Entry{recent})[0]
+}
+
+// Switch finishes the entry in progress for the timer and starts entry as
+// one save. It returns the finished and the started entry.
+func (tracker *Local) Switch(timer string, entry Entry) (Entry, Entry) {
+	finished, started := switchEntries(tracker.LoadEntries(), timer, entry, tracker.Rounding, tracker.Clients, tracker.location())
+	saved := tracker.SaveEntries([]Entry{finished, started})
+	return saved[0], saved[1]
+}
+
+// switchEntries finishes the entry in progress for the timer when entry
+// starts, so that the two entries do not overlap or leave a gap. If
+// rounding applies at finish time, the finished entry is rounded the same
+// way as by Finish. Entry continues the timer of the finished entry if it
+// is for the same client and project. Otherwise it keeps its own timer,
+// which must not be running. Times in messages are shown in loc.
+func switchEntries(entries []Entry, timer string, entry Entry, policy RoundingPolicy, clients []Client, loc *time.Location) (Entry, Entry) {
+	recent, err := RunningEntry(entries, timer)
+	if err != nil {
+		log.Fatal("Unable to switch: ", err)
+	}
+	if err := recent.End(0, entry.StartedAt); err != nil {
+		log.Fatal(err)
+	}
+	if entry.ClientID == recent.ClientID && entry.ProjectID == recent.ProjectID {
+		entry.Timer = recent.Timer
+	} else {
+		// The finished entry no longer blocks its timer.
+		others := []Entry{}
+		for _, other := range entries {
+			if !(other.InProgress() && SameTimer(other, recent)) {
+				others = append(others, other)
+			}
+		}
+		if running, ok := runningConflict(others, entry); ok {
+			log.Fatal(fmt.Sprintf("An entry is already in progress for this timer:\n %v", running.StringIn(loc)))
+		}
+	}
+	if rounding := policy.For(clients, recent); rounding.Apply == ApplyFinish {
+		if err := rounding.RoundEntry(&recent); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return recent, entry
 }
 
-// LoadEntries loads all Entries from a local file.
+// LoadEntries loads all Entries from a local file. Deleted entries are
+// not included.
 func (tracker *Local) LoadEntries() []Entry {
+	entries := []Entry{}
+	for _, entry := range tracker.loadAll() {
+		if !entry.IsDeleted() {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// loadAll loads all Entries from a local file including the tombstones
+// of deleted entries.
+func (tracker *Local) loadAll() []Entry {
 	logLocation := tracker.LogLocation
 	if strings.Contains(logLocation, "~") {
 		expanded, err := homedir.Expand(logLocation)
@@ -83,7 +170,8 @@ func (tracker *Local) LoadEntries() []Entry {
 	return entries
 }
 
-// SaveEntries saves a list of entries to a local file.
+// SaveEntries saves a list of entries to a local file. It returns the
+// saved entries with the IDs of new entries.
 func (tracker *Local) SaveEntries(entries []Entry) []Entry {
 	logLocation := tracker.LogLocation
 	if strings.Contains(logLocation, "~") {
@@ -93,18 +181,28 @@ func (tracker *Local) SaveEntries(entries []Entry) []Entry {
 		}
 		logLocation = expanded
 	}
-	current := tracker.LoadEntries()
+	current := tracker.loadAll()
+	checkEntries(entries, current, tracker.Force)
+	entries = tracker.recordHistory(current, entries)
+	// New entries get their IDs before they are merged so that the saved
+	// entries are returned with them.
+	nextID := NextID(append(append([]Entry{}, current...), entries...))
+	for ix := range entries {
+		if entries[ix].ID == 0 {
+			entries[ix].ID = nextID
+			nextID++
+		}
+	}
 	updated, err := UpdateEntries(current, entries, "ID")
-	nextID := NextID(updated)
+	if err != nil {
+		log.Fatal(err)
+	}
 	for ix := range updated {
 		if updated[ix].ID == 0 {
 			updated[ix].ID = nextID
 			nextID++
 		}
 	}
-	if err != nil {
-		log.Fatal(err)
-	}
 	SortEntries(updated)
 
 	data, err := json.MarshalIndent(updated, "", "  ")
@@ -120,6 +218,75 @@ func (tracker *Local) SaveEntries(entries []Entry) []Entry {
 	return entries
 }
 
+// Import saves entries that were read from another source. Their history
+// records OriginImport as the origin.
+func (tracker *Local) Import(entries []Entry) []Entry {
+	origin := tracker.Origin
+	tracker.Origin = OriginImport
+	defer func() { tracker.Origin = origin }()
+	return tracker.SaveEntries(entries)
+}
+
+// Delete marks entries as deleted. The entries are kept in the log as
+// tombstones so that they are not restored when syncing.
+func (tracker *Local) Delete(entries []Entry) []Entry {
+	now := time.Now().UTC()
+	deleted := []Entry{}
+	for _, entry := range entries {
+		if entry.ID == 0 {
+			log.Fatal("Unable to delete entry because it has not been saved: \n", entry.StringIn(tracker.location()))
+		}
+		if !entry.IsDeleted() {
+			entry.DeletedAt = now
+		}
+		deleted = append(deleted, entry)
+	}
+	return tracker.SaveEntries(deleted)
+}
+
+// recordHistory appends a Change to each entry that is new or differs
+// from its saved version.
+func (tracker *Local) recordHistory(current []Entry, entries []Entry) []Entry {
+	origin := tracker.Origin
+	if origin == "" {
+		origin = OriginLocal
+	}
+	saved := map[int]Entry{}
+	for _, entry := range current {
+		saved[entry.ID] = entry
+	}
+
+	now := time.Now().UTC()
+	res := make([]Entry, len(entries))
+	for ix, entry := range entries {
+		prev, exists := saved[entry.ID]
+		if entry.ID == 0 || !exists {
+			entry.History = append(entry.History, Change{
+				At:      now,
+				Action:  ActionCreate,
+				Command: tracker.Command,
+				Origin:  origin,
+			})
+		} else if fields := Diff(prev, entry); len(fields) > 0 {
+			action := ActionUpdate
+			if entry.IsDeleted() && !prev.IsDeleted() {
+				action = ActionDelete
+			}
+			entry.History = append(append([]Change{}, prev.History...), Change{
+				At:      now,
+				Action:  action,
+				Command: tracker.Command,
+				Origin:  origin,
+				Fields:  fields,
+			})
+		} else {
+			entry.History = prev.History
+		}
+		res[ix] = entry
+	}
+	return res
+}
+
 // Exists checks if the file 'name' exists.
 func Exists(name string) (bool, error) {
 	_, err := os.Stat(name)
@@ -0,0 +1,120 @@
+package track
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func tempLocal(t *testing.T) (Local, func()) {
+	dir, err := ioutil.TempDir("", "ttrack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Local{LogLocation: filepath.Join(dir, "log.json")}, func() { os.RemoveAll(dir) }
+}
+
+func TestDeleteKeepsTombstone(t *testing.T) {
+	tracker, cleanup := tempLocal(t)
+	defer cleanup()
+
+	tracker.SaveEntries(mockEntries())
+	entries := tracker.LoadEntries()
+	tracker.Delete(entries[1:2])
+
+	entries = tracker.LoadEntries()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %v", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.ExternalID == 456 {
+			t.Errorf("Deleted entry was loaded: %v", entry)
+		}
+	}
+
+	all := tracker.loadAll()
+	if len(all) != 4 {
+		t.Fatalf("Expected 4 entries including tombstones, got %v", len(all))
+	}
+	history := all[1].History
+	if !all[1].IsDeleted() || history[len(history)-1].Action != ActionDelete {
+		t.Errorf("Expected tombstone with delete action, got %v", all[1])
+	}
+
+	// Saving again must not drop the tombstone.
+	tracker.SaveEntries(entries)
+	if len(tracker.loadAll()) != 4 {
+		t.Errorf("Tombstone was dropped when saving entries")
+	}
+}
+
+func TestSaveEntriesReturnsIDs(t *testing.T) {
+	tracker, cleanup := tempLocal(t)
+	defer cleanup()
+
+	saved := tracker.SaveEntries(mockEntries())
+	ids := map[int]bool{}
+	for _, entry := range saved {
+		if entry.ID == 0 || ids[entry.ID] {
+			t.Errorf("Expected a new unique ID, got %v", entry.ID)
+		}
+		ids[entry.ID] = true
+	}
+
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	if entry := tracker.Start(Entry{StartedAt: started, Billable: true}); entry.ID != NextID(saved) {
+		t.Errorf("Expected the started entry to have ID %d, got %v", NextID(saved), entry.ID)
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	tracker, cleanup := tempLocal(t)
+	defer cleanup()
+
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	tracker.Start(Entry{StartedAt: started, Description: "first", Billable: true})
+	finished, next := tracker.Switch("", Entry{StartedAt: started.Add(45 * time.Minute), Description: "second", Billable: true})
+	if finished.Description != "first" || finished.Duration != 45*60 || !finished.FinishedAt.Equal(next.StartedAt) {
+		t.Errorf("Expected the first entry to finish when the second starts, got %v and %v", finished, next)
+	}
+	if next.Description != "second" || !next.InProgress() {
+		t.Errorf("Expected a new entry in progress, got %v", next)
+	}
+
+	entries := tracker.LoadEntries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", entries)
+	}
+	if recent := MostRecentEntry(entries); recent.Description != "second" || !recent.InProgress() {
+		t.Errorf("Expected the second entry to be in progress, got %v", recent)
+	}
+
+	tracker.Rounding = RoundingPolicy{Default: Rounding{Mode: RoundUp, Increment: 15 * time.Minute, Apply: ApplyFinish}}
+	finished, next = tracker.Switch("", Entry{StartedAt: started.Add(50 * time.Minute), Description: "third", Billable: true})
+	if finished.Duration != 15*60 || !finished.FinishedAt.Equal(next.StartedAt) || !next.StartedAt.Equal(started.Add(50*time.Minute)) {
+		t.Errorf("Expected the rounded entry to end when the next one starts at the switch time, got %v and %v", finished, next)
+	}
+}
+
+func TestSwitchTimers(t *testing.T) {
+	tracker, cleanup := tempLocal(t)
+	defer cleanup()
+
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	tracker.Start(Entry{StartedAt: started, ClientID: 1, Timer: "acme", Billable: true})
+	tracker.Start(Entry{StartedAt: started, ClientID: 2, Timer: "beta", Billable: true})
+
+	finished, next := tracker.Switch("beta", Entry{StartedAt: started.Add(time.Hour), ClientID: 2, Timer: "other", Billable: true})
+	if finished.Timer != "beta" || finished.InProgress() || next.Timer != "beta" {
+		t.Errorf("Expected the beta timer to continue for the same client, got %v and %v", finished, next)
+	}
+	finished, next = tracker.Switch("beta", Entry{StartedAt: started.Add(2 * time.Hour), ClientID: 3, Timer: "gamma", Billable: true})
+	if finished.Timer != "beta" || next.Timer != "gamma" {
+		t.Errorf("Expected the new client to use its own timer, got %v and %v", finished, next)
+	}
+	if running := RunningEntries(tracker.LoadEntries()); len(running) != 2 {
+		t.Errorf("Expected the acme and gamma timers to be running, got %v", running)
+	}
+}
@@ -0,0 +1,101 @@
+package track
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rate is an hourly rate for the entries of a client, a project or a
+// tag. Rates without a client, project or tag apply to all entries.
+type Rate struct {
+	// Client is the nickname of a client.
+	Client   string
+	Project  int
+	Tag      string
+	Hourly   float64
+	Currency string
+}
+
+// String returns a string representation of the Rate object.
+func (rate *Rate) String() string {
+	return fmt.Sprintf("%.2f %s/h", rate.Hourly, rate.Currency)
+}
+
+// Amount returns the amount earned over a duration.
+func (rate *Rate) Amount(d time.Duration) float64 {
+	return rate.Hourly * d.Hours()
+}
+
+// specificity ranks rates so that tag rates override project rates and
+// project rates override client rates.
+func (rate *Rate) specificity() int {
+	res := 0
+	if rate.Tag != "" {
+		res += 4
+	}
+	if rate.Project != 0 {
+		res += 2
+	}
+	if rate.Client != "" {
+		res++
+	}
+	return res
+}
+
+func (rate *Rate) matches(clients []Client, entry Entry) bool {
+	if rate.Tag != "" && !entry.HasTag(rate.Tag) {
+		return false
+	}
+	if rate.Project != 0 && rate.Project != entry.ProjectID {
+		return false
+	}
+	if rate.Client != "" {
+		client, ok := ClientFor(clients, entry)
+		if !ok || !strings.EqualFold(client.Nickname, rate.Client) {
+			return false
+		}
+	}
+	return true
+}
+
+// RateFor finds the most specific rate for an entry. If more than one
+// rate is equally specific, the first one is used.
+func RateFor(rates []Rate, clients []Client, entry Entry) (Rate, bool) {
+	var (
+		res   Rate
+		found bool
+	)
+	for _, rate := range rates {
+		if !rate.matches(clients, entry) {
+			continue
+		}
+		if !found || rate.specificity() > res.specificity() {
+			res, found = rate, true
+		}
+	}
+	return res, found
+}
+
+// Money holds amounts by currency.
+type Money map[string]float64
+
+// Add adds an amount in a currency.
+func (money Money) Add(amount float64, currency string) {
+	money[currency] += amount
+}
+
+// String returns the amounts sorted by currency, e.g. "1200.00 USD".
+func (money Money) String() string {
+	currencies := []string{}
+	for currency := range money {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	amounts := []string{}
+	for _, currency := range currencies {
+		amounts = append(amounts, strings.TrimSpace(fmt.Sprintf("%.2f %s", money[currency], currency)))
+	}
+	return strings.Join(amounts, ", ")
+}
@@ -0,0 +1,45 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateFor(t *testing.T) {
+	clients := []Client{{Nickname: "acme", ClientID: 1}, {Nickname: "globex", ClientID: 2}}
+	rates := []Rate{
+		{Hourly: 50, Currency: "USD"},
+		{Client: "acme", Hourly: 100, Currency: "USD"},
+		{Client: "acme", Project: 42, Hourly: 150, Currency: "USD"},
+		{Tag: "review", Hourly: 90, Currency: "EUR"},
+	}
+	tests := []struct {
+		entry    Entry
+		expected float64
+	}{
+		{Entry{ClientID: 2}, 50},
+		{Entry{ClientID: 1}, 100},
+		{Entry{ClientID: 1, ProjectID: 42}, 150},
+		{Entry{ClientID: 1, ProjectID: 42, Tags: []string{"Review"}}, 90},
+	}
+	for _, test := range tests {
+		rate, ok := RateFor(rates, clients, test.entry)
+		if !ok || rate.Hourly != test.expected {
+			t.Errorf("Expected rate %v for %v, got %v", test.expected, test.entry, rate)
+		}
+	}
+
+	if _, ok := RateFor(rates[1:], clients, Entry{ClientID: 2}); ok {
+		t.Error("Expected no rate for a client without rates.")
+	}
+}
+
+func TestMoney(t *testing.T) {
+	rate := Rate{Hourly: 120, Currency: "USD"}
+	money := Money{}
+	money.Add(rate.Amount(90*time.Minute), rate.Currency)
+	money.Add(10, "EUR")
+	if got := money.String(); got != "10.00 EUR, 180.00 USD" {
+		t.Errorf("Unexpected amounts: %s", got)
+	}
+}
@@ -0,0 +1,343 @@
+package track
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hdoupe/ttrack/timeexpr"
+)
+
+// Schedule frequencies.
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// Recurring is a template for entries that repeat on a schedule, e.g. a
+// daily standup.
+type Recurring struct {
+	Name        string
+	Description string
+	// Client is the nickname of a client.
+	Client string
+	// Schedule is an RRULE-like rule, e.g. "FREQ=WEEKLY;BYDAY=MO,WE,FR".
+	Schedule string
+	// Start is the time of day that the entries start, e.g. "09:30".
+	Start    string
+	Duration time.Duration
+	Tags     []string
+	NoBill   bool
+	// From is the first day of the schedule as YYYY-MM-DD. Intervals are
+	// counted from it.
+	From string
+}
+
+// Schedule is a parsed recurrence rule. It supports the FREQ, INTERVAL,
+// BYDAY, BYMONTHDAY and UNTIL parts of RRULEs.
+type Schedule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	// ByMonthDay lists days of the month. Negative days count from the
+	// end of the month, e.g. -1 is the last day.
+	ByMonthDay []int
+	From       time.Time
+	Until      time.Time
+	// WeekStart is the first day of the weeks that weekly intervals are
+	// counted in. ParseSchedule sets it to Monday.
+	WeekStart time.Weekday
+}
+
+var ruleDays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// ParseSchedule parses a recurrence rule like
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO" that starts on from, which may be zero
+// if the rule does not depend on it. Dates are midnight in from's
+// location.
+func ParseSchedule(rule string, from time.Time) (Schedule, error) {
+	schedule := Schedule{Interval: 1, From: from, WeekStart: time.Monday}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"), ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return Schedule{}, fmt.Errorf("invalid schedule %q, parts must look like KEY=VALUE, got %q", rule, part)
+		}
+		key, value := strings.ToUpper(strings.TrimSpace(kv[0])), strings.ToUpper(strings.TrimSpace(kv[1]))
+		switch key {
+		case "FREQ":
+			if value != Daily && value != Weekly && value != Monthly {
+				return Schedule{}, fmt.Errorf("invalid schedule %q, FREQ must be one of %s, %s or %s", rule, Daily, Weekly, Monthly)
+			}
+			schedule.Freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return Schedule{}, fmt.Errorf("invalid schedule %q, INTERVAL must be a positive number", rule)
+			}
+			schedule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := ruleDays[strings.TrimSpace(day)]
+				if !ok {
+					return Schedule{}, fmt.Errorf("invalid schedule %q, unknown day %q", rule, day)
+				}
+				schedule.ByDay = append(schedule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(day))
+				if err != nil || n == 0 || n > 31 || n < -31 {
+					return Schedule{}, fmt.Errorf("invalid schedule %q, unknown day of the month %q", rule, day)
+				}
+				schedule.ByMonthDay = append(schedule.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := parseRuleDate(value, from.Location())
+			if err != nil {
+				return Schedule{}, fmt.Errorf("invalid schedule %q, UNTIL must be a date like 20211231", rule)
+			}
+			schedule.Until = until
+		default:
+			return Schedule{}, fmt.Errorf("invalid schedule %q, %s is not supported", rule, key)
+		}
+	}
+
+	switch {
+	case schedule.Freq == "":
+		return Schedule{}, fmt.Errorf("invalid schedule %q, FREQ is required", rule)
+	case schedule.Interval > 1 && from.IsZero():
+		return Schedule{}, fmt.Errorf("invalid schedule %q, INTERVAL needs a from date", rule)
+	case schedule.Freq == Weekly && len(schedule.ByDay) == 0 && from.IsZero():
+		return Schedule{}, fmt.Errorf("invalid schedule %q, weekly schedules need BYDAY or a from date", rule)
+	case schedule.Freq == Monthly && len(schedule.ByDay) == 0 && len(schedule.ByMonthDay) == 0 && from.IsZero():
+		return Schedule{}, fmt.Errorf("invalid schedule %q, monthly schedules need BYMONTHDAY, BYDAY or a from date", rule)
+	}
+	return schedule, nil
+}
+
+func parseRuleDate(value string, loc *time.Location) (time.Time, error) {
+	if len(value) > 8 && value[8] == 'T' {
+		// Ignore the time of UNTIL=20211231T235959Z.
+		value = value[:8]
+	}
+	for _, layout := range []string{"20060102", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// Occurs returns true if the schedule has an occurrence on day.
+func (schedule Schedule) Occurs(day time.Time) bool {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	from := schedule.From
+	if !from.IsZero() {
+		from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, day.Location())
+		if day.Before(from) {
+			return false
+		}
+	}
+	if !schedule.Until.IsZero() && daysBetween(schedule.Until, day) > 0 {
+		return false
+	}
+
+	byDay := schedule.ByDay
+	byMonthDay := schedule.ByMonthDay
+	switch schedule.Freq {
+	case Daily:
+		if !from.IsZero() && daysBetween(from, day)%schedule.Interval != 0 {
+			return false
+		}
+	case Weekly:
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{from.Weekday()}
+		}
+		if !from.IsZero() && daysBetween(timeexpr.StartOfWeek(from, schedule.WeekStart), timeexpr.StartOfWeek(day, schedule.WeekStart))/7%schedule.Interval != 0 {
+			return false
+		}
+	case Monthly:
+		if len(byDay) == 0 && len(byMonthDay) == 0 {
+			byMonthDay = []int{from.Day()}
+		}
+		months := (day.Year()-from.Year())*12 + int(day.Month()-from.Month())
+		if !from.IsZero() && months%schedule.Interval != 0 {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if len(byDay) > 0 && !containsWeekday(byDay, day.Weekday()) {
+		return false
+	}
+	if len(byMonthDay) > 0 {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		found := false
+		for _, n := range byMonthDay {
+			if n == day.Day() || (n < 0 && last+n+1 == day.Day()) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// daysBetween counts calendar days from a to b, which are both midnight.
+func daysBetween(a time.Time, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// RecurringOptions declares the parameters for RecurringEntries.
+type RecurringOptions struct {
+	// Since and Until limit the occurrences to those that start on or
+	// after Since and finish before or at Until.
+	Since time.Time
+	Until time.Time
+	// Location is used for the days and start times of the schedules.
+	// Defaults to time.Local.
+	Location *time.Location
+	// WeekStart is the first day of the weeks that weekly intervals are
+	// counted in.
+	WeekStart time.Weekday
+	Clients   []Client
+	// Holidays lists days without occurrences as YYYY-MM-DD.
+	Holidays []string
+}
+
+// RecurringEntries creates the entries for the occurrences of the
+// templates that are missing from entries. An occurrence is missing if
+// there is no entry for the same client with the same description on
+// that day. Holidays are skipped.
+func RecurringEntries(templates []Recurring, entries []Entry, opts RecurringOptions) ([]Entry, error) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	holidays := map[string]bool{}
+	for _, holiday := range opts.Holidays {
+		day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(holiday), loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday %q, use YYYY-MM-DD", holiday)
+		}
+		holidays[day.Format("2006-01-02")] = true
+	}
+	existing := map[string]bool{}
+	for _, entry := range entries {
+		existing[occurrenceKey(entry, loc)] = true
+	}
+
+	res := []Entry{}
+	for _, template := range templates {
+		occurrences, err := template.entries(opts, loc)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range occurrences {
+			key := occurrenceKey(entry, loc)
+			if holidays[entry.StartedAt.In(loc).Format("2006-01-02")] || existing[key] {
+				continue
+			}
+			existing[key] = true
+			res = append(res, entry)
+		}
+	}
+	SortEntries(res)
+	return res, nil
+}
+
+// entries lists the occurrences of the template between opts.Since and
+// opts.Until.
+func (template Recurring) entries(opts RecurringOptions, loc *time.Location) ([]Entry, error) {
+	name := template.Name
+	if name == "" {
+		name = template.Description
+	}
+	var from time.Time
+	if template.From != "" {
+		t, err := time.ParseInLocation("2006-01-02", template.From, loc)
+		if err != nil {
+			return nil, fmt.Errorf("recurring entry %q: invalid from date %q, use YYYY-MM-DD", name, template.From)
+		}
+		from = t
+	}
+	schedule, err := ParseSchedule(template.Schedule, from)
+	if err != nil {
+		return nil, fmt.Errorf("recurring entry %q: %v", name, err)
+	}
+	schedule.WeekStart = opts.WeekStart
+	start, err := parseClock(template.Start)
+	if err != nil {
+		return nil, fmt.Errorf("recurring entry %q: start must look like 09:30, got %q", name, template.Start)
+	}
+	if template.Duration <= 0 {
+		return nil, fmt.Errorf("recurring entry %q: duration must be positive", name)
+	}
+	var client Client
+	if template.Client != "" {
+		clients := FilterClients(opts.Clients, Client{Nickname: template.Client})
+		if len(clients) != 1 {
+			return nil, fmt.Errorf("recurring entry %q: no client with nickname %q", name, template.Client)
+		}
+		client = clients[0]
+	}
+	description := template.Description
+	if description == "" {
+		description = template.Name
+	}
+
+	res := []Entry{}
+	since := opts.Since.In(loc)
+	for day := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, loc); day.Before(opts.Until); day = day.AddDate(0, 0, 1) {
+		if !schedule.Occurs(day) {
+			continue
+		}
+		// The start time is built from the wall clock so that it is
+		// correct on days when daylight saving time changes.
+		startedAt := time.Date(day.Year(), day.Month(), day.Day(), int(start/time.Hour), int(start%time.Hour/time.Minute), 0, 0, loc)
+		finishedAt := startedAt.Add(template.Duration)
+		if startedAt.Before(opts.Since) || finishedAt.After(opts.Until) {
+			continue
+		}
+		entry := Entry{
+			StartedAt:   startedAt.UTC(),
+			Description: description,
+			ClientID:    client.ClientID,
+			ProjectID:   client.ProjectID,
+			Billable:    !template.NoBill,
+		}
+		entry.AddTags(template.Tags...)
+		if err := entry.End(template.Duration, time.Time{}); err != nil {
+			return nil, err
+		}
+		res = append(res, entry)
+	}
+	return res, nil
+}
+
+// occurrenceKey identifies the day, client and description of an entry.
+func occurrenceKey(entry Entry, loc *time.Location) string {
+	return fmt.Sprintf("%s|%d|%d|%s", entry.StartedAt.In(loc).Format("2006-01-02"), entry.ClientID, entry.ProjectID, strings.ToLower(strings.TrimSpace(entry.Description)))
+}
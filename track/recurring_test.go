@@ -0,0 +1,144 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	schedule, err := ParseSchedule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;UNTIL=20210331", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Freq != Weekly || schedule.Interval != 2 || len(schedule.ByDay) != 2 || schedule.Until.Day() != 31 {
+		t.Errorf("Unexpected schedule: %+v", schedule)
+	}
+	for _, bad := range []string{"", "BYDAY=MO", "FREQ=YEARLY", "FREQ=DAILY;INTERVAL=0", "FREQ=WEEKLY;BYDAY=XX", "FREQ=DAILY;COUNT=3", "FREQ=MONTHLY;BYMONTHDAY=32"} {
+		if _, err := ParseSchedule(bad, from); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+	if _, err := ParseSchedule("FREQ=DAILY;INTERVAL=2", time.Time{}); err == nil {
+		t.Error("Expected an error for an interval without a from date")
+	}
+}
+
+func TestScheduleOccurs(t *testing.T) {
+	// Monday, March 1, 2021.
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2021, 3, d, 0, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		rule  string
+		days  []int
+		not   []int
+		start time.Time
+	}{
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", []int{1, 5, 8}, []int{6, 7}, time.Time{}},
+		{"FREQ=DAILY;INTERVAL=3", []int{1, 4, 7}, []int{2, 3, 5}, from},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", []int{1, 5, 15, 19}, []int{8, 12, 2}, from},
+		{"FREQ=WEEKLY", []int{1, 8}, []int{2, 7}, from},
+		{"FREQ=MONTHLY;BYMONTHDAY=15,-1", []int{15, 31}, []int{1, 30}, time.Time{}},
+		{"FREQ=DAILY;UNTIL=2021-03-10", []int{1, 10}, []int{11}, time.Time{}},
+	}
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.rule, c.start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range c.days {
+			if !schedule.Occurs(day(d)) {
+				t.Errorf("Expected %s to occur on March %d", c.rule, d)
+			}
+		}
+		for _, d := range c.not {
+			if schedule.Occurs(day(d)) {
+				t.Errorf("Expected %s not to occur on March %d", c.rule, d)
+			}
+		}
+	}
+}
+
+func TestRecurringEntries(t *testing.T) {
+	clients := []Client{{Nickname: "acme", ClientID: 1}}
+	templates := []Recurring{
+		{Name: "standup", Description: "Daily standup", Client: "acme", Schedule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", Start: "09:30", Duration: 15 * time.Minute, Tags: []string{"meeting"}},
+	}
+	since := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	existing := []Entry{
+		{ID: 1, StartedAt: since.Add(9*time.Hour + 35*time.Minute), FinishedAt: since.Add(10 * time.Hour), Duration: 1500, Description: "daily standup", ClientID: 1},
+	}
+	opts := RecurringOptions{
+		Since:    since,
+		Until:    since.AddDate(0, 0, 7),
+		Location: time.UTC,
+		Clients:  clients,
+		Holidays: []string{"2021-03-03"},
+	}
+
+	entries, err := RecurringEntries(templates, existing, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tuesday, Thursday and Friday: Monday is logged and Wednesday is a
+	// holiday.
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %v", entries)
+	}
+	first := entries[0]
+	if !first.StartedAt.Equal(time.Date(2021, 3, 2, 9, 30, 0, 0, time.UTC)) || first.Duration != 15*60 || first.ClientID != 1 || !first.HasTag("meeting") || !first.Billable {
+		t.Errorf("Unexpected entry: %v", first)
+	}
+
+	opts.Until = time.Date(2021, 3, 5, 9, 40, 0, 0, time.UTC)
+	if entries, _ := RecurringEntries(templates, existing, opts); len(entries) != 2 {
+		t.Errorf("Expected the occurrence in progress to be skipped, got %v", entries)
+	}
+
+	templates[0].Client = "other"
+	if _, err := RecurringEntries(templates, existing, opts); err == nil {
+		t.Error("Expected an error for an unknown client")
+	}
+}
+
+func TestRecurringEntriesCalendar(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data is not available")
+	}
+	templates := []Recurring{{Name: "standup", Schedule: "FREQ=DAILY", Start: "09:00", Duration: 15 * time.Minute}}
+	// Daylight saving time starts on Sunday, March 14, 2021.
+	since := time.Date(2021, 3, 13, 0, 0, 0, 0, loc)
+	opts := RecurringOptions{Since: since, Until: since.AddDate(0, 0, 3), Location: loc}
+	entries, err := RecurringEntries(templates, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %v", entries)
+	}
+	for _, entry := range entries {
+		if started := entry.StartedAt.In(loc); started.Hour() != 9 || started.Minute() != 0 {
+			t.Errorf("Expected the entry to start at 09:00, got %v", started)
+		}
+	}
+
+	// Every other week from Monday, March 1, 2021 counts weeks that start
+	// on WeekStart.
+	templates = []Recurring{{Name: "review", Schedule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO", From: "2021-03-01", Start: "09:00", Duration: time.Hour}}
+	opts = RecurringOptions{Since: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2021, 3, 16, 0, 0, 0, 0, time.UTC), Location: time.UTC}
+	for weekStart, days := range map[time.Weekday][]int{time.Monday: {1, 7, 15}, time.Sunday: {1, 14, 15}} {
+		opts.WeekStart = weekStart
+		entries, err := RecurringEntries(templates, nil, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []int{}
+		for _, entry := range entries {
+			got = append(got, entry.StartedAt.Day())
+		}
+		if len(got) != len(days) || got[0] != days[0] || got[1] != days[1] || got[2] != days[2] {
+			t.Errorf("(%v) Expected entries on March %v, got %v", weekStart, days, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package track
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resume returns a new entry that starts at startedAt with the
+// description, client, project, tags, billable status and timer of entry.
+func Resume(entry Entry, startedAt time.Time) Entry {
+	return Entry{
+		StartedAt:   startedAt,
+		Description: entry.Description,
+		ClientID:    entry.ClientID,
+		ProjectID:   entry.ProjectID,
+		Tags:        append([]string{}, entry.Tags...),
+		Billable:    entry.Billable,
+		Timer:       entry.Timer,
+	}
+}
+
+// Reopen clears the finish time of the most recent entry of the timer, or
+// of any timer if timer is empty, so that it is in progress again. An
+// error is returned if the most recent entry is in progress, finished
+// more than maxBreak before now, or another entry is in progress for its
+// timer.
+func Reopen(entries []Entry, timer string, now time.Time, maxBreak time.Duration) (Entry, error) {
+	candidates := entries
+	if timer != "" {
+		candidates = []Entry{}
+		for _, entry := range entries {
+			if SameTimer(entry, Entry{Timer: timer}) {
+				candidates = append(candidates, entry)
+			}
+		}
+	}
+	recent := MostRecentEntry(candidates)
+	if recent.IsZero() {
+		return Entry{}, fmt.Errorf("there are no entries to continue")
+	}
+	if recent.InProgress() {
+		return Entry{}, fmt.Errorf("the most recent entry is still in progress:\n%v", recent.StringIn(now.Location()))
+	}
+	if running, ok := runningConflict(entries, recent); ok {
+		return Entry{}, fmt.Errorf("an entry is already in progress for this timer:\n%v", running.StringIn(now.Location()))
+	}
+	if pause := now.Sub(recent.FinishedAt); pause > maxBreak {
+		return Entry{}, fmt.Errorf(
+			"the most recent entry finished %v ago, which is longer than the %v threshold; use resume to start a new entry",
+			pause.Round(time.Minute),
+			maxBreak,
+		)
+	}
+	recent.FinishedAt = time.Time{}
+	recent.Duration = 0
+	return recent, nil
+}
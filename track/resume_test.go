@@ -0,0 +1,68 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResume(t *testing.T) {
+	entry := mockEntries()[2]
+	entry.ClientID, entry.ProjectID, entry.Tags, entry.Timer = 1, 2, []string{"dev"}, "acme"
+	startedAt := entry.FinishedAt.Add(time.Hour)
+
+	resumed := Resume(entry, startedAt)
+	if resumed.ID != 0 || resumed.ExternalID != 0 || !resumed.InProgress() || !resumed.StartedAt.Equal(startedAt) {
+		t.Errorf("Expected a new entry in progress, got %v", resumed)
+	}
+	if resumed.Description != entry.Description || resumed.ClientID != 1 || resumed.ProjectID != 2 || !resumed.HasTag("dev") || resumed.Timer != "acme" {
+		t.Errorf("Expected the details of %v, got %v", entry, resumed)
+	}
+	resumed.Tags[0] = "changed"
+	if !entry.HasTag("dev") {
+		t.Error("Expected the tags to be copied")
+	}
+}
+
+func TestReopen(t *testing.T) {
+	entries := mockEntries()
+	recent := MostRecentEntry(entries)
+
+	reopened, err := Reopen(entries, "", recent.FinishedAt.Add(10*time.Minute), 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.ID != recent.ID || !reopened.InProgress() || reopened.Duration != 0 {
+		t.Errorf("Expected entry %d to be in progress, got %v", recent.ID, reopened)
+	}
+
+	if _, err := Reopen(entries, "", recent.FinishedAt.Add(20*time.Minute), 15*time.Minute); err == nil {
+		t.Error("Expected an error when the break is longer than the threshold")
+	}
+	if _, err := Reopen([]Entry{reopened}, "", time.Now(), 15*time.Minute); err == nil {
+		t.Error("Expected an error when the most recent entry is in progress")
+	}
+	if _, err := Reopen(nil, "", time.Now(), 15*time.Minute); err == nil {
+		t.Error("Expected an error without entries")
+	}
+}
+
+func TestReopenTimers(t *testing.T) {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{ID: 1, StartedAt: started, FinishedAt: started.Add(time.Hour), Duration: 3600, Timer: "acme"},
+		{ID: 2, StartedAt: started.Add(30 * time.Minute), Timer: "oncall"},
+		{ID: 3, StartedAt: started.Add(time.Hour), FinishedAt: started.Add(2 * time.Hour), Duration: 3600, Timer: "oncall"},
+	}
+	now := started.Add(2*time.Hour + 5*time.Minute)
+
+	reopened, err := Reopen(entries, "ACME", now, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.ID != 1 {
+		t.Errorf("Expected the most recent acme entry to be reopened, got %v", reopened)
+	}
+	if _, err := Reopen(entries, "oncall", now, 2*time.Hour); err == nil {
+		t.Error("Expected an error when another entry is in progress for the timer")
+	}
+}
@@ -0,0 +1,194 @@
+package track
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rounding modes.
+const (
+	RoundNone    = "none"
+	RoundNearest = "nearest"
+	RoundUp      = "up"
+	RoundDown    = "down"
+)
+
+// When rounding is applied.
+const (
+	// ApplyFinish rounds the duration of entries when they are finished.
+	ApplyFinish = "finish"
+	// ApplyReport keeps the recorded durations and rounds them in reports
+	// and exports.
+	ApplyReport = "report"
+)
+
+// Rounding describes how durations are rounded for billing.
+type Rounding struct {
+	Mode      string
+	Increment time.Duration
+	// Minimum is the shortest duration that is billed.
+	Minimum time.Duration
+	Apply   string
+}
+
+// String returns a string representation of the Rounding object.
+func (rounding Rounding) String() string {
+	if rounding.IsZero() {
+		return RoundNone
+	}
+	res := rounding.Mode
+	if rounding.Mode != "" && rounding.Mode != RoundNone {
+		res = fmt.Sprintf("%s to %v", rounding.Mode, rounding.Increment)
+	}
+	if rounding.Minimum > 0 {
+		res = fmt.Sprintf("%s, minimum %v", res, rounding.Minimum)
+	}
+	return res
+}
+
+// IsZero returns true if durations are not changed.
+func (rounding Rounding) IsZero() bool {
+	return (rounding.Mode == "" || rounding.Mode == RoundNone) && rounding.Minimum == 0
+}
+
+// Validate checks the mode, increment and when the rounding is applied.
+func (rounding Rounding) Validate() error {
+	switch rounding.Mode {
+	case "", RoundNone:
+	case RoundNearest, RoundUp, RoundDown:
+		if rounding.Increment <= 0 {
+			return fmt.Errorf("rounding %s requires a positive increment", rounding.Mode)
+		}
+	default:
+		return fmt.Errorf(
+			"unknown rounding mode %q, use one of %s",
+			rounding.Mode,
+			strings.Join([]string{RoundNone, RoundNearest, RoundUp, RoundDown}, ", "),
+		)
+	}
+	if rounding.Minimum < 0 {
+		return fmt.Errorf("minimum billable duration must not be negative, got %v", rounding.Minimum)
+	}
+	switch rounding.Apply {
+	case "", ApplyFinish, ApplyReport:
+	default:
+		return fmt.Errorf("rounding must apply at %q or %q, got %q", ApplyFinish, ApplyReport, rounding.Apply)
+	}
+	return nil
+}
+
+// Round rounds a duration to the increment and then raises it to the
+// minimum. Zero durations stay zero.
+func (rounding Rounding) Round(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	if rounding.Increment > 0 {
+		switch rounding.Mode {
+		case RoundNearest:
+			d = d.Round(rounding.Increment)
+		case RoundUp:
+			if rem := d % rounding.Increment; rem > 0 {
+				d += rounding.Increment - rem
+			}
+		case RoundDown:
+			d = d.Truncate(rounding.Increment)
+		}
+	}
+	if d < rounding.Minimum {
+		d = rounding.Minimum
+	}
+	return d
+}
+
+// RoundEntry rounds the duration of a finished entry. The entry keeps its
+// finish time so that the rounded time does not overlap the entries after
+// it.
+func (rounding Rounding) RoundEntry(entry *Entry) error {
+	if entry.InProgress() || rounding.IsZero() {
+		return nil
+	}
+	rounded := rounding.Round(time.Duration(entry.Duration) * time.Second)
+	if rounded < 0 {
+		return fmt.Errorf("duration must not be negative, got %v", rounded)
+	}
+	entry.Duration = int(rounded.Seconds())
+	return nil
+}
+
+// RoundingPolicy is the default rounding and the rounding for clients
+// that override it.
+type RoundingPolicy struct {
+	Default Rounding
+	// Clients maps client nicknames to their rounding. Fields that are
+	// not set use the default.
+	Clients map[string]Rounding
+}
+
+// Validate checks the default rounding and the rounding of each client.
+func (policy RoundingPolicy) Validate() error {
+	if err := policy.Default.Validate(); err != nil {
+		return err
+	}
+	for nickname := range policy.Clients {
+		if err := policy.client(nickname).Validate(); err != nil {
+			return fmt.Errorf("client %s: %v", nickname, err)
+		}
+	}
+	return nil
+}
+
+func (policy RoundingPolicy) client(nickname string) Rounding {
+	res := policy.Default
+	for key, rounding := range policy.Clients {
+		if !strings.EqualFold(key, nickname) {
+			continue
+		}
+		if rounding.Mode != "" {
+			res.Mode = rounding.Mode
+		}
+		if rounding.Increment != 0 {
+			res.Increment = rounding.Increment
+		}
+		if rounding.Minimum != 0 {
+			res.Minimum = rounding.Minimum
+		}
+		if rounding.Apply != "" {
+			res.Apply = rounding.Apply
+		}
+	}
+	return res
+}
+
+// For returns the rounding for an entry's client.
+func (policy RoundingPolicy) For(clients []Client, entry Entry) Rounding {
+	if client, ok := ClientFor(clients, entry); ok {
+		return policy.client(client.Nickname)
+	}
+	return policy.Default
+}
+
+// Rounded returns the duration of an entry for billing. Entries whose
+// rounding applies at finish time were rounded when they were finished,
+// so their duration is not changed.
+func (policy RoundingPolicy) Rounded(clients []Client, entry Entry) time.Duration {
+	rounding := policy.For(clients, entry)
+	if rounding.Apply == ApplyFinish {
+		return Elapsed(entry)
+	}
+	return rounding.Round(Elapsed(entry))
+}
+
+// IsZero returns true if no durations are rounded.
+func (policy RoundingPolicy) IsZero() bool {
+	if !policy.Default.IsZero() {
+		return false
+	}
+	for nickname := range policy.Clients {
+		if !policy.client(nickname).IsZero() {
+			return false
+		}
+	}
+	return true
+}
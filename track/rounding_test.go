@@ -0,0 +1,92 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		rounding Rounding
+		d        time.Duration
+		expected time.Duration
+	}{
+		{Rounding{}, 7 * time.Minute, 7 * time.Minute},
+		{Rounding{Mode: RoundNearest, Increment: 15 * time.Minute}, 7 * time.Minute, 0},
+		{Rounding{Mode: RoundNearest, Increment: 15 * time.Minute}, 8 * time.Minute, 15 * time.Minute},
+		{Rounding{Mode: RoundUp, Increment: 6 * time.Minute}, 61 * time.Minute, 66 * time.Minute},
+		{Rounding{Mode: RoundUp, Increment: 6 * time.Minute}, 60 * time.Minute, 60 * time.Minute},
+		{Rounding{Mode: RoundDown, Increment: 15 * time.Minute}, 44 * time.Minute, 30 * time.Minute},
+		{Rounding{Mode: RoundDown, Increment: 15 * time.Minute, Minimum: 15 * time.Minute}, 10 * time.Minute, 15 * time.Minute},
+		{Rounding{Minimum: 30 * time.Minute}, 0, 0},
+	}
+	for _, test := range tests {
+		if got := test.rounding.Round(test.d); got != test.expected {
+			t.Errorf("%v: expected %v to round to %v, got %v", test.rounding, test.d, test.expected, got)
+		}
+	}
+}
+
+func TestRoundingValidate(t *testing.T) {
+	invalid := []Rounding{
+		{Mode: "ceil", Increment: time.Minute},
+		{Mode: RoundUp},
+		{Minimum: -time.Minute},
+		{Apply: "sync"},
+	}
+	for _, rounding := range invalid {
+		if err := rounding.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", rounding)
+		}
+	}
+	if err := (Rounding{Mode: RoundUp, Increment: time.Minute, Apply: ApplyFinish}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRoundingPolicy(t *testing.T) {
+	clients := []Client{{Nickname: "acme", ClientID: 1}, {Nickname: "globex", ClientID: 2}}
+	policy := RoundingPolicy{
+		Default: Rounding{Mode: RoundUp, Increment: 15 * time.Minute, Minimum: 30 * time.Minute},
+		Clients: map[string]Rounding{"acme": {Increment: 6 * time.Minute}},
+	}
+	entry := func(clientID int, d time.Duration) Entry {
+		started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+		return Entry{ClientID: clientID, StartedAt: started, FinishedAt: started.Add(d), Duration: int(d.Seconds())}
+	}
+	if got := policy.Rounded(clients, entry(1, 31*time.Minute)); got != 36*time.Minute {
+		t.Errorf("Expected acme to round up to 6 minutes, got %v", got)
+	}
+	if got := policy.Rounded(clients, entry(1, 10*time.Minute)); got != 30*time.Minute {
+		t.Errorf("Expected acme to use the default minimum, got %v", got)
+	}
+	if got := policy.Rounded(clients, entry(2, 31*time.Minute)); got != 45*time.Minute {
+		t.Errorf("Expected globex to use the default rounding, got %v", got)
+	}
+
+	// Entries rounded at finish time are not rounded again.
+	policy.Default.Apply = ApplyFinish
+	policy.Default.Minimum = 25 * time.Minute
+	if got := policy.Rounded(clients, entry(2, 25*time.Minute)); got != 25*time.Minute {
+		t.Errorf("Expected an entry rounded at finish time to keep its duration, got %v", got)
+	}
+}
+
+func TestFinishRounding(t *testing.T) {
+	tracker, cleanup := tempLocal(t)
+	defer cleanup()
+	tracker.Rounding = RoundingPolicy{Default: Rounding{Mode: RoundUp, Increment: 15 * time.Minute, Apply: ApplyFinish}}
+
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	tracker.Start(Entry{StartedAt: started, Billable: true})
+	entry := tracker.Finish(Entry{FinishedAt: started.Add(20 * time.Minute), Billable: true})
+	if entry.Duration != 30*60 || !entry.FinishedAt.Equal(started.Add(20*time.Minute)) {
+		t.Errorf("Expected the entry to be rounded up to 30 minutes and finish at 9:20, got %v", entry)
+	}
+
+	// The rounded time does not block the next entry.
+	next := tracker.Start(Entry{StartedAt: started.Add(20 * time.Minute), Billable: true})
+	if !next.StartedAt.Equal(started.Add(20 * time.Minute)) {
+		t.Errorf("Expected the next entry to start at 9:20, got %v", next)
+	}
+}
@@ -0,0 +1,215 @@
+package track
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sheetDate   = "2006-01-02"
+	sheetClock  = "15:04"
+	sheetNew    = "new"
+	sheetFollow = "^"
+	sheetNone   = "-"
+)
+
+const sheetHelp = `# Edit the entries below, then save and close the file to apply the changes.
+#
+# Each line is: <id> <date> <start> <finish> <client> <description>
+#
+#   id       entry ID, or "new" to add an entry
+#   date     YYYY-MM-DD
+#   start    HH:MM, or "^" to start when the previous line finishes
+#   finish   HH:MM, or "-" if the entry is in progress
+#   client   client nickname, <client id>/<project id>, or "-" for none
+#
+# Lines may be reordered. Removing a line deletes the entry.
+# Lines starting with '#' are ignored.
+`
+
+// FormatSheet writes entries in the plain text format used for editing
+// entries interactively.
+func FormatSheet(entries []Entry, clients []Client, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString(sheetHelp)
+	b.WriteString("\n")
+	for _, entry := range entries {
+		finish := sheetNone
+		if !entry.InProgress() {
+			finish = entry.FinishedAt.In(loc).Format(sheetClock)
+		}
+		fmt.Fprintf(
+			&b,
+			"%-6d %s  %s  %s  %s  %s\n",
+			entry.ID,
+			entry.StartedAt.In(loc).Format(sheetDate),
+			entry.StartedAt.In(loc).Format(sheetClock),
+			finish,
+			sheetClient(entry, clients),
+			entry.Description,
+		)
+	}
+	return b.String()
+}
+
+func sheetClient(entry Entry, clients []Client) string {
+	if client, ok := ClientFor(clients, entry); ok && strings.TrimSpace(client.Nickname) != "" && !strings.ContainsAny(client.Nickname, " \t") {
+		return client.Nickname
+	}
+	if entry.ClientID == 0 && entry.ProjectID == 0 {
+		return sheetNone
+	}
+	return fmt.Sprintf("%d/%d", entry.ClientID, entry.ProjectID)
+}
+
+// ParseSheet reads entries written by FormatSheet. Existing entries are
+// looked up in the original entries so that fields which are not part of
+// the sheet, and times that were not changed, are kept as is. New
+// entries have an ID of zero.
+func ParseSheet(text string, original []Entry, clients []Client, loc *time.Location) ([]Entry, error) {
+	byID := map[int]Entry{}
+	for _, entry := range original {
+		byID[entry.ID] = entry
+	}
+
+	res := []Entry{}
+	seen := map[int]bool{}
+	var previous Entry
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields, description := splitSheetLine(line, 5)
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("line %d: expected <id> <date> <start> <finish> <client> <description>, got %q", lineNo, line)
+		}
+
+		entry := Entry{Billable: true}
+		if fields[0] != sheetNew {
+			id, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: id must be an integer or %q, got %q", lineNo, sheetNew, fields[0])
+			}
+			orig, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("line %d: entry %d is not one of the entries being edited", lineNo, id)
+			}
+			if seen[id] {
+				return nil, fmt.Errorf("line %d: entry %d is listed more than once", lineNo, id)
+			}
+			seen[id] = true
+			entry = orig
+		}
+
+		date, err := time.ParseInLocation(sheetDate, fields[1], loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q, expected YYYY-MM-DD", lineNo, fields[1])
+		}
+
+		var startedAt time.Time
+		if fields[2] == sheetFollow {
+			if previous.IsZero() || previous.InProgress() {
+				return nil, fmt.Errorf("line %d: %q requires a finished entry on the line before", lineNo, sheetFollow)
+			}
+			startedAt = previous.FinishedAt
+		} else {
+			startedAt, err = sheetTime(date, fields[2], loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid start time %q, expected HH:MM", lineNo, fields[2])
+			}
+		}
+		// Durations are only recomputed if the times change so that
+		// unchanged lines keep rounded durations.
+		timesChanged := entry.ID == 0
+		if !sameMinute(entry.StartedAt, startedAt) {
+			entry.StartedAt = startedAt.UTC()
+			timesChanged = true
+		}
+
+		if fields[3] == sheetNone {
+			entry.FinishedAt = time.Time{}
+			entry.Duration = 0
+		} else {
+			finishedAt, err := sheetTime(startedAt.In(loc), fields[3], loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid finish time %q, expected HH:MM", lineNo, fields[3])
+			}
+			if finishedAt.Before(startedAt) {
+				// The entry finished after midnight.
+				finishedAt = finishedAt.AddDate(0, 0, 1)
+			}
+			if !sameMinute(entry.FinishedAt, finishedAt) {
+				entry.FinishedAt = finishedAt.UTC()
+				timesChanged = true
+			}
+			if timesChanged {
+				if err := entry.End(0, entry.FinishedAt); err != nil {
+					return nil, fmt.Errorf("line %d: %v", lineNo, err)
+				}
+			}
+		}
+
+		switch {
+		case fields[4] == sheetNone:
+			entry.ClientID, entry.ProjectID = 0, 0
+		case strings.Contains(fields[4], "/"):
+			ids := strings.SplitN(fields[4], "/", 2)
+			clientID, clientErr := strconv.Atoi(ids[0])
+			projectID, projectErr := strconv.Atoi(ids[1])
+			if clientErr != nil || projectErr != nil {
+				return nil, fmt.Errorf("line %d: invalid client %q, expected <client id>/<project id>", lineNo, fields[4])
+			}
+			entry.ClientID, entry.ProjectID = clientID, projectID
+		default:
+			matched := FilterClients(clients, Client{Nickname: fields[4]})
+			if len(matched) != 1 {
+				return nil, fmt.Errorf("line %d: unknown client %q", lineNo, fields[4])
+			}
+			entry.ClientID, entry.ProjectID = matched[0].ClientID, matched[0].ProjectID
+		}
+
+		entry.Description = description
+		res = append(res, entry)
+		previous = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// splitSheetLine splits the first n whitespace separated fields from the
+// rest of the line.
+func splitSheetLine(line string, n int) ([]string, string) {
+	fields := []string{}
+	rest := strings.TrimSpace(line)
+	for len(fields) < n && rest != "" {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		fields = append(fields, rest[:end])
+		rest = strings.TrimSpace(rest[end:])
+	}
+	return fields, rest
+}
+
+// sheetTime combines the date of day with the HH:MM clock value.
+func sheetTime(day time.Time, clock string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation(sheetClock, clock, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
+}
+
+func sameMinute(a time.Time, b time.Time) bool {
+	return a.Truncate(time.Minute).Equal(b.Truncate(time.Minute))
+}
@@ -0,0 +1,98 @@
+package track
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSheetRoundTrip(t *testing.T) {
+	entries := mockEntries()
+	for ix := range entries {
+		entries[ix].ID = ix + 1
+	}
+	clients := []Client{{Nickname: "acme"}}
+	text := FormatSheet(entries, clients, time.UTC)
+
+	parsed, err := ParseSheet(text, entries, clients, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(entries) {
+		t.Fatalf("Expected %d entries, got %d", len(entries), len(parsed))
+	}
+	for ix := range entries {
+		if fields := Diff(entries[ix], parsed[ix]); len(fields) > 0 {
+			t.Errorf("Entry %d changed: %v", entries[ix].ID, fields)
+		}
+	}
+}
+
+func TestParseSheetKeepsDurations(t *testing.T) {
+	entries := mockEntries()[0:2]
+	for ix := range entries {
+		entries[ix].ID = ix + 1
+	}
+	// The first entry was rounded up by 15 minutes.
+	entries[0].Duration += 15 * 60
+	text := FormatSheet(entries, []Client{}, time.UTC)
+	text = strings.Replace(text, "15:00", "14:30", 1)
+
+	parsed, err := ParseSheet(text, entries, []Client{}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed[0].Duration != entries[0].Duration {
+		t.Errorf("Expected unchanged entry to keep its duration, got %d", parsed[0].Duration)
+	}
+	if parsed[1].Duration != 90*60 {
+		t.Errorf("Expected duration of changed entry to be 1h30m, got %d", parsed[1].Duration)
+	}
+}
+
+func TestParseSheetChanges(t *testing.T) {
+	entries := mockEntries()[0:2]
+	for ix := range entries {
+		entries[ix].ID = ix + 1
+	}
+	text := strings.Join([]string{
+		"# comment",
+		"2    2020-11-21  13:00  13:30  -  Write   some tests",
+		"new  2020-11-21  ^      14:00  1/2  Review",
+	}, "\n")
+
+	parsed, err := ParseSheet(text, entries, []Client{}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(parsed))
+	}
+	if parsed[0].ID != 2 || parsed[0].Description != "Write   some tests" {
+		t.Errorf("Unexpected entry: %v", parsed[0])
+	}
+	added := parsed[1]
+	if added.ID != 0 || added.ClientID != 1 || added.ProjectID != 2 || added.Description != "Review" {
+		t.Errorf("Unexpected new entry: %v", added)
+	}
+	if !added.StartedAt.Equal(parsed[0].FinishedAt) || added.Duration != 30*60 {
+		t.Errorf("Unexpected times for new entry: %v", added)
+	}
+}
+
+func TestParseSheetErrors(t *testing.T) {
+	entries := mockEntries()[0:1]
+	entries[0].ID = 1
+	for _, text := range []string{
+		"1 2020-11-21 10:00",
+		"7 2020-11-21 10:00 11:00 - Unknown entry",
+		"1 2020-11-21 10:00 11:00 - Once\n1 2020-11-21 11:00 12:00 - Twice",
+		"1 2020-11-21 9am 11:00 - Bad time",
+		"new 2020-11-21 ^ 11:00 - Nothing before",
+		"1 2020-11-21 10:00 11:00 nobody Unknown client",
+	} {
+		if _, err := ParseSheet(text, entries, []Client{}, time.UTC); err == nil {
+			t.Errorf("Expected error for %q", text)
+		}
+	}
+}
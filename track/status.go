@@ -0,0 +1,67 @@
+package track
+
+import "time"
+
+// Timer describes an entry in progress.
+type Timer struct {
+	// Entry is the entry in progress.
+	Entry Entry
+	// Client is the nickname of the client of the entry.
+	Client string
+	// Elapsed is the time since the entry was started, excluding breaks.
+	Elapsed time.Duration
+	// Paused is true if the entry is on a break.
+	Paused bool
+}
+
+// Status describes the entries in progress and the time recorded today
+// and this week.
+type Status struct {
+	// Running is true if an entry is in progress.
+	Running bool
+	// Timer is the most recently started entry in progress.
+	Timer
+	// Timers lists all of the entries in progress by their start time.
+	Timers []Timer
+	Today  time.Duration
+	Week   time.Duration
+}
+
+// NewStatus finds the entries in progress and totals the entries that
+// started since today and since week. Entries in progress count up to
+// now.
+func NewStatus(entries []Entry, clients []Client, now time.Time, today time.Time, week time.Time) Status {
+	var status Status
+	for _, entry := range RunningEntries(entries) {
+		timer := Timer{Entry: entry, Elapsed: elapsedAt(entry, now), Paused: entry.Paused()}
+		if client, ok := ClientFor(clients, entry); ok {
+			timer.Client = client.Nickname
+		}
+		status.Timers = append(status.Timers, timer)
+		status.Running = true
+		status.Timer = timer
+	}
+	for _, entry := range entries {
+		if !entry.StartedAt.Before(today) {
+			status.Today += elapsedAt(entry, now)
+		}
+		if !entry.StartedAt.Before(week) {
+			status.Week += elapsedAt(entry, now)
+		}
+	}
+	return status
+}
+
+// Select returns the status of the timer. Running is false if the timer
+// is not running.
+func (status Status) Select(timer string) Status {
+	status.Running = false
+	status.Timer = Timer{}
+	for _, t := range status.Timers {
+		if SameTimer(t.Entry, Entry{Timer: timer}) {
+			status.Running = true
+			status.Timer = t
+		}
+	}
+	return status
+}
@@ -0,0 +1,58 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewStatus(t *testing.T) {
+	now := time.Date(2021, 3, 31, 14, 20, 0, 0, time.UTC)
+	today := now.Add(-3 * time.Hour)
+	week := now.AddDate(0, 0, -3)
+	clients := []Client{{Nickname: "acme", ClientID: 1}}
+	entries := []Entry{
+		{ID: 1, StartedAt: week.Add(time.Hour), FinishedAt: week.Add(3 * time.Hour), Duration: 7200},
+		{ID: 2, StartedAt: today, FinishedAt: today.Add(time.Hour), Duration: 3600},
+		{ID: 3, StartedAt: now.Add(-30 * time.Minute), ClientID: 1, Description: "review"},
+	}
+
+	status := NewStatus(entries, clients, now, today, week)
+	if !status.Running || status.Entry.ID != 3 || status.Client != "acme" {
+		t.Errorf("Expected entry 3 for acme to be running, got %+v", status)
+	}
+	if status.Elapsed != 30*time.Minute {
+		t.Errorf("Expected 30m elapsed, got %v", status.Elapsed)
+	}
+	if status.Today != 90*time.Minute {
+		t.Errorf("Expected 1h30m today, got %v", status.Today)
+	}
+	if status.Week != 210*time.Minute {
+		t.Errorf("Expected 3h30m this week, got %v", status.Week)
+	}
+
+	status = NewStatus(entries[:2], clients, now, today, week)
+	if status.Running || status.Elapsed != 0 || status.Today != time.Hour {
+		t.Errorf("Expected no entry to be running, got %+v", status)
+	}
+}
+
+func TestNewStatusTimers(t *testing.T) {
+	now := time.Date(2021, 3, 31, 14, 20, 0, 0, time.UTC)
+	entries := []Entry{
+		{ID: 1, StartedAt: now.Add(-2 * time.Hour), Timer: "oncall"},
+		{ID: 2, StartedAt: now.Add(-time.Hour), Timer: "acme"},
+	}
+	status := NewStatus(entries, nil, now, now.Add(-3*time.Hour), now.AddDate(0, 0, -1))
+	if len(status.Timers) != 2 || status.Entry.ID != 2 {
+		t.Errorf("Expected 2 timers with entry 2 most recent, got %+v", status)
+	}
+	if status.Today != 3*time.Hour {
+		t.Errorf("Expected 3h today, got %v", status.Today)
+	}
+	if selected := status.Select("OnCall"); !selected.Running || selected.Entry.ID != 1 {
+		t.Errorf("Expected the oncall timer, got %+v", selected)
+	}
+	if selected := status.Select("other"); selected.Running {
+		t.Errorf("Expected no timer, got %+v", selected)
+	}
+}
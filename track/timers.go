@@ -0,0 +1,73 @@
+package track
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SameTimer returns true if the entries belong to the same timer. Entries
+// belong to the timer named by their Timer label, which is not case
+// sensitive. Only one entry per timer can be in progress and entries of
+// different timers may overlap, e.g. an on-call shift that runs alongside
+// project work. Entries without a label belong to the default timer,
+// which can not start while any other entry is in progress.
+func SameTimer(a Entry, b Entry) bool {
+	return strings.EqualFold(a.Timer, b.Timer)
+}
+
+// RunningEntries returns the entries that are in progress sorted by their
+// start time.
+func RunningEntries(entries []Entry) []Entry {
+	res := []Entry{}
+	for _, entry := range entries {
+		if !entry.IsZero() && entry.InProgress() {
+			res = append(res, entry)
+		}
+	}
+	SortEntries(res)
+	return res
+}
+
+// RunningEntry returns the entry in progress for the timer. If timer is
+// empty, the only entry in progress is returned. An error is returned if
+// no entry or more than one entry matches.
+func RunningEntry(entries []Entry, timer string) (Entry, error) {
+	running := []Entry{}
+	for _, entry := range RunningEntries(entries) {
+		if timer == "" || SameTimer(entry, Entry{Timer: timer}) {
+			running = append(running, entry)
+		}
+	}
+	switch {
+	case len(running) == 0 && timer != "":
+		return Entry{}, fmt.Errorf("there is no entry in progress for timer %q", timer)
+	case len(running) == 0:
+		return Entry{}, fmt.Errorf("there is no entry in progress")
+	case len(running) > 1:
+		return Entry{}, fmt.Errorf("%d timers are running (%s), choose one with --timer", len(running), strings.Join(timerNames(running), ", "))
+	}
+	return running[0], nil
+}
+
+// runningConflict returns the entry in progress that prevents entry from
+// starting, i.e. an entry of the same timer or any entry if entry belongs
+// to the default timer.
+func runningConflict(entries []Entry, entry Entry) (Entry, bool) {
+	for _, running := range RunningEntries(entries) {
+		if entry.Timer == "" || SameTimer(running, entry) {
+			return running, true
+		}
+	}
+	return Entry{}, false
+}
+
+func timerNames(entries []Entry) []string {
+	res := make([]string, len(entries))
+	for ix, entry := range entries {
+		res[ix] = entry.Timer
+		if res[ix] == "" {
+			res[ix] = "default"
+		}
+	}
+	return res
+}
@@ -0,0 +1,63 @@
+package track
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunningEntry(t *testing.T) {
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{ID: 1, StartedAt: started, FinishedAt: started.Add(time.Hour), Duration: 3600},
+		{ID: 2, StartedAt: started.Add(time.Hour), Timer: "oncall"},
+	}
+	if entry, err := RunningEntry(entries, ""); err != nil || entry.ID != 2 {
+		t.Errorf("Expected the only entry in progress, got %v, %v", entry, err)
+	}
+	if _, err := RunningEntry(entries, "acme"); err == nil {
+		t.Error("Expected an error for a timer that is not running")
+	}
+
+	entries = append(entries, Entry{ID: 3, StartedAt: started.Add(2 * time.Hour), Timer: "acme"})
+	if _, err := RunningEntry(entries, ""); err == nil {
+		t.Error("Expected an error when several timers are running")
+	}
+	if entry, err := RunningEntry(entries, "ACME"); err != nil || entry.ID != 3 {
+		t.Errorf("Expected entry 3 for timer acme, got %v, %v", entry, err)
+	}
+
+	if _, ok := runningConflict(entries, Entry{Timer: "other"}); ok {
+		t.Error("Expected a new timer to start")
+	}
+	if running, ok := runningConflict(entries, Entry{Timer: "oncall"}); !ok || running.ID != 2 {
+		t.Errorf("Expected entry 2 to block the oncall timer, got %v", running)
+	}
+	if _, ok := runningConflict(entries, Entry{}); !ok {
+		t.Error("Expected the default timer to be blocked by any entry in progress")
+	}
+}
+
+func TestConcurrentTimers(t *testing.T) {
+	tracker, cleanup := tempLocal(t)
+	defer cleanup()
+
+	started := time.Date(2021, 3, 30, 9, 0, 0, 0, time.UTC)
+	tracker.Start(Entry{StartedAt: started, Description: "on call", Timer: "oncall", Billable: true})
+	tracker.Start(Entry{StartedAt: started.Add(time.Hour), Description: "project", Timer: "acme", Billable: true})
+
+	finished := tracker.Finish(Entry{FinishedAt: started.Add(2 * time.Hour), Timer: "acme", Billable: true})
+	if finished.Description != "project" || finished.Duration != 3600 {
+		t.Errorf("Expected the acme timer to finish after 1h, got %v", finished)
+	}
+	finished = tracker.Finish(Entry{FinishedAt: started.Add(3 * time.Hour), Billable: true})
+	if finished.Description != "on call" || finished.Duration != 3*3600 {
+		t.Errorf("Expected the only timer left to finish after 3h, got %v", finished)
+	}
+	if problems := Check(tracker.LoadEntries(), 0); len(problems) != 0 {
+		t.Errorf("Expected overlapping timers to be valid, got %v", problems)
+	}
+
+	if err := Validate(Entry{StartedAt: started.Add(90 * time.Minute), FinishedAt: started.Add(3 * time.Hour), Timer: "acme"}, tracker.LoadEntries()); err == nil {
+		t.Error("Expected an overlap within the acme timer")
+	}
+}
@@ -4,6 +4,8 @@ package track
 type Tracker interface {
 	Start(entry Entry) Entry
 	Finish(entry Entry) Entry
+	Switch(timer string, entry Entry) (Entry, Entry)
 	LoadEntries() []Entry
 	SaveEntries(entries []Entry) []Entry
+	Delete(entries []Entry) []Entry
 }
@@ -0,0 +1,192 @@
+package track
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ValidationError lists the problems found with an entry.
+type ValidationError struct {
+	Entry    Entry
+	Problems []string
+}
+
+// Error returns the entry and its problems.
+func (err *ValidationError) Error() string {
+	return fmt.Sprintf("Entry %s is not valid:\n  - %s", describe(err.Entry), strings.Join(err.Problems, "\n  - "))
+}
+
+// describe returns a short description of an entry for messages.
+func describe(entry Entry) string {
+	if entry.ID == 0 {
+		return fmt.Sprintf("%q", entry.Description)
+	}
+	return fmt.Sprintf("%d (%q)", entry.ID, entry.Description)
+}
+
+// end returns the time the entry finished, or the current time if it is
+// still in progress.
+func end(entry Entry) time.Time {
+	return endAt(entry, time.Now().UTC())
+}
+
+// endAt returns the time the entry finished, or now if it is still in
+// progress.
+func endAt(entry Entry, now time.Time) time.Time {
+	if entry.InProgress() {
+		if now.Before(entry.StartedAt) {
+			return entry.StartedAt
+		}
+		return now
+	}
+	return entry.FinishedAt
+}
+
+// Overlaps returns true if the time periods of the entries overlap.
+// Entries that are in progress are treated as ending now.
+func Overlaps(a Entry, b Entry) bool {
+	return a.StartedAt.Before(end(b)) && b.StartedAt.Before(end(a))
+}
+
+// problems lists the problems with the entry's own values.
+func problems(entry Entry) []string {
+	res := []string{}
+	if entry.StartedAt.IsZero() {
+		res = append(res, "it does not have a start time")
+	}
+	if !entry.InProgress() && entry.FinishedAt.Before(entry.StartedAt) {
+		res = append(res, "it finishes before it starts")
+	}
+	if entry.Duration < 0 {
+		res = append(res, fmt.Sprintf("it has a negative duration (%ds)", entry.Duration))
+	}
+	for _, problem := range breakProblems(entry) {
+		res = append(res, "its "+problem)
+	}
+	return res
+}
+
+// Validate checks that the entry's times are consistent and that it does
+// not overlap with any of the other entries of the same timer. Deleted
+// entries and entries with the same ID are ignored.
+func Validate(entry Entry, others []Entry) error {
+	if entry.IsDeleted() {
+		return nil
+	}
+	found := problems(entry)
+	for _, other := range others {
+		if other.IsDeleted() || (entry.ID != 0 && other.ID == entry.ID) {
+			continue
+		}
+		if SameTimer(entry, other) && Overlaps(entry, other) {
+			found = append(found, fmt.Sprintf("it overlaps with entry %s", describe(other)))
+		}
+	}
+	if len(found) > 0 {
+		return &ValidationError{Entry: entry, Problems: found}
+	}
+	return nil
+}
+
+// ValidateEntries validates entries that are about to be saved against
+// each other and the current entries.
+func ValidateEntries(entries []Entry, current []Entry) error {
+	saving := map[int]bool{}
+	for _, entry := range entries {
+		if entry.ID != 0 {
+			saving[entry.ID] = true
+		}
+	}
+	others := []Entry{}
+	for _, entry := range current {
+		if !saving[entry.ID] {
+			others = append(others, entry)
+		}
+	}
+
+	errs := []string{}
+	for ix, entry := range entries {
+		compare := append([]Entry{}, others...)
+		compare = append(compare, entries[:ix]...)
+		compare = append(compare, entries[ix+1:]...)
+		if err := Validate(entry, compare); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// checkEntries stops the program if the entries are not valid, unless
+// force is set.
+func checkEntries(entries []Entry, current []Entry, force bool) {
+	if force {
+		return
+	}
+	if err := ValidateEntries(entries, current); err != nil {
+		log.Fatal(err, "\nUse --force to save anyway.")
+	}
+}
+
+// Problem is an issue found by Check.
+type Problem struct {
+	Entry   Entry
+	Message string
+}
+
+// String returns a string representation of the Problem object.
+func (problem *Problem) String() string {
+	return fmt.Sprintf("Entry %s: %s", describe(problem.Entry), problem.Message)
+}
+
+// Check scans entries for overlaps within a timer, negative or zero durations,
+// duplicate external IDs, and entries that are longer than maxDuration.
+// A maxDuration of zero skips the long entry check.
+func Check(entries []Entry, maxDuration time.Duration) []Problem {
+	sorted := []Entry{}
+	for _, entry := range entries {
+		if !entry.IsDeleted() {
+			sorted = append(sorted, entry)
+		}
+	}
+	SortEntries(sorted)
+
+	res := []Problem{}
+	externalIDs := map[int]Entry{}
+	for ix, entry := range sorted {
+		for _, message := range problems(entry) {
+			res = append(res, Problem{Entry: entry, Message: message})
+		}
+		if !entry.InProgress() && entry.Duration == 0 {
+			res = append(res, Problem{Entry: entry, Message: "it has a duration of zero"})
+		}
+		if maxDuration > 0 && end(entry).Sub(entry.StartedAt) > maxDuration {
+			msg := fmt.Sprintf("it is longer than %v", maxDuration)
+			res = append(res, Problem{Entry: entry, Message: msg})
+		}
+		if entry.ExternalID != 0 {
+			if other, exists := externalIDs[entry.ExternalID]; exists {
+				msg := fmt.Sprintf("it has the same external ID as entry %s", describe(other))
+				res = append(res, Problem{Entry: entry, Message: msg})
+			} else {
+				externalIDs[entry.ExternalID] = entry
+			}
+		}
+		// Entries are sorted by start time, so only the following entries
+		// that start before this one ends can overlap.
+		for _, other := range sorted[ix+1:] {
+			if !other.StartedAt.Before(end(entry)) {
+				break
+			}
+			if SameTimer(entry, other) && Overlaps(entry, other) {
+				msg := fmt.Sprintf("it overlaps with entry %s", describe(other))
+				res = append(res, Problem{Entry: entry, Message: msg})
+			}
+		}
+	}
+	return res
+}
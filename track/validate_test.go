@@ -0,0 +1,65 @@
+package track
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndRejectsInvalidTimes(t *testing.T) {
+	entry := mockEntries()[0]
+	if err := entry.End(-time.Hour, time.Time{}); err == nil {
+		t.Errorf("Expected error for negative duration")
+	}
+	if err := entry.End(0, entry.StartedAt.Add(-time.Minute)); err == nil {
+		t.Errorf("Expected error for finishing before starting")
+	}
+}
+
+func TestValidateEntries(t *testing.T) {
+	entries := mockEntries()
+	for ix := range entries {
+		entries[ix].ID = ix + 1
+	}
+	if err := ValidateEntries(entries[0:1], entries); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	overlapping := entries[1]
+	overlapping.ID = 0
+	overlapping.StartedAt = entries[0].StartedAt.Add(time.Hour)
+	err := ValidateEntries([]Entry{overlapping}, entries)
+	if err == nil || !strings.Contains(err.Error(), "overlaps with entry 1") {
+		t.Errorf("Expected overlap error, got %v", err)
+	}
+
+	backwards := entries[2]
+	backwards.FinishedAt = backwards.StartedAt.Add(-time.Hour)
+	if err := ValidateEntries([]Entry{backwards}, entries); err == nil {
+		t.Errorf("Expected error for entry that finishes before it starts")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	entries := mockEntries()
+	for ix := range entries {
+		entries[ix].ID = ix + 1
+	}
+	if problems := Check(entries, 12*time.Hour); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+
+	entries[1].StartedAt = entries[0].StartedAt.Add(time.Hour)
+	entries[2].ExternalID = entries[3].ExternalID
+	entries[3].Duration = 0
+	problems := Check(entries, time.Hour)
+	messages := []string{}
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+	for _, expected := range []string{"overlaps with entry 2", "same external ID", "duration of zero", "longer than 1h0m0s"} {
+		if !strings.Contains(strings.Join(messages, "\n"), expected) {
+			t.Errorf("Expected a problem containing %q, got %v", expected, messages)
+		}
+	}
+}